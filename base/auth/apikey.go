@@ -0,0 +1,100 @@
+// File: `API Key Authentication File` base/auth/apikey.go
+package PhoeniciaDigitalAuth
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// An API key is only ever stored as the hex SHA-256 of the key | generate one with:
+// key=$(openssl rand -hex 32) && echo -n $key | sha256sum
+type apiKey struct {
+	name  string
+	hash  []byte
+	roles []string
+}
+
+var apiKeys []apiKey
+
+// When enabled keys are also looked up in the api_keys table of the Postgres Database
+var apiKeysInPostgres bool
+
+// Parse AUTH_API_KEYS (comma separated name:sha256hex:roles entries) & AUTH_API_KEYS_POSTGRES
+// roles is a | separated list ex: operator or viewer|admin | keys without roles are viewers
+func loadAPIKeys() error {
+	settings := PhoeniciaDigitalConfig.Config().Auth
+
+	for _, entry := range settings.Auth_api_keys {
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return fmt.Errorf("API key entry must be name:sha256hex:roles got: %s", entry)
+		}
+		name, hexHash := fields[0], fields[1]
+
+		hash, err := hex.DecodeString(hexHash)
+		if err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("API key %s hash is not a hex SHA-256", name)
+		}
+
+		roles := []string{RoleViewer}
+		if len(fields) == 3 {
+			if roles, err = parseRoles(fields[2], "|"); err != nil {
+				return fmt.Errorf("API key %s: %w", name, err)
+			}
+		}
+
+		apiKeys = append(apiKeys, apiKey{name: name, hash: hash, roles: roles})
+	}
+
+	apiKeysInPostgres = settings.Auth_api_keys_postgres
+	return nil
+}
+
+// Check the key against the configured keys first & the api_keys table second
+func verifyAPIKey(ctx context.Context, key string) (Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+
+	// Every configured key is compared in constant time so timing does not reveal which one is closest
+	var matched *apiKey
+	for i := range apiKeys {
+		if subtle.ConstantTimeCompare(sum[:], apiKeys[i].hash) == 1 {
+			matched = &apiKeys[i]
+		}
+	}
+	if matched != nil {
+		return Principal{Subject: matched.name, Method: "api_key", Roles: matched.roles}, nil
+	}
+
+	// AUTH_API_KEYS_POSTGRES is checked to need POSTGRES_ENABLED while the config is loaded
+	if apiKeysInPostgres {
+		// A failed lookup (database down, query file invalid, ...) is not the client's fault so it is not a 401
+		row, err := PhoeniciaDigitalDatabase.Postgres.QueryRow(ctx, "select_api_key", hex.EncodeToString(sum[:]))
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: looking up API key: %w", errVerificationUnavailable, err)
+		}
+
+		var name, role string
+		if err := row.Scan(&name, &role); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return Principal{}, fmt.Errorf("%w: reading API key: %w", errVerificationUnavailable, err)
+			}
+			return Principal{}, errInvalidCredentials
+		}
+
+		roles, err := parseRoles(role, "|")
+		if err != nil {
+			return Principal{}, fmt.Errorf("API key %s: %w", name, err)
+		}
+		return Principal{Subject: name, Method: "api_key", Roles: roles}, nil
+	}
+
+	return Principal{}, errInvalidCredentials
+}
@@ -0,0 +1,121 @@
+// File: `API Key Authentication Tests File` base/auth/apikey_test.go
+package PhoeniciaDigitalAuth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// Replace the configured keys for a test | restored once the test ends
+func useAPIKeys(t *testing.T, keys []apiKey, inPostgres bool) {
+	t.Helper()
+	previousKeys, previousInPostgres := apiKeys, apiKeysInPostgres
+	apiKeys, apiKeysInPostgres = keys, inPostgres
+	t.Cleanup(func() { apiKeys, apiKeysInPostgres = previousKeys, previousInPostgres })
+}
+
+func testAPIKey(name string, key string, roles ...string) apiKey {
+	sum := sha256.Sum256([]byte(key))
+	return apiKey{name: name, hash: sum[:], roles: roles}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	keys := []apiKey{testAPIKey("dashboard", "dashboard-key", RoleOperator), testAPIKey("ops", "ops-key", RoleAdmin)}
+
+	tests := []struct {
+		name       string
+		inPostgres bool
+		key        string
+		want       Principal
+		wantErr    error
+	}{
+		{
+			name: "configured key",
+			key:  "ops-key",
+			want: Principal{Subject: "ops", Method: "api_key", Roles: []string{RoleAdmin}},
+		},
+		{
+			name:    "unknown key",
+			key:     "guess",
+			wantErr: errInvalidCredentials,
+		},
+		{
+			name:       "configured keys are checked before the api_keys table",
+			inPostgres: true,
+			key:        "dashboard-key",
+			want:       Principal{Subject: "dashboard", Method: "api_key", Roles: []string{RoleOperator}},
+		},
+		// Postgres is not enabled in the tests so the lookup fails like it would with the database down
+		{
+			name:       "failed api_keys lookup",
+			inPostgres: true,
+			key:        "guess",
+			wantErr:    errVerificationUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useAPIKeys(t, keys, test.inPostgres)
+
+			got, err := verifyAPIKey(context.Background(), test.key)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("error = %v want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("principal = %+v want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeStatus(t *testing.T) {
+	previousEnabled := enabled
+	enabled = true
+	t.Cleanup(func() { enabled = previousEnabled })
+
+	keys := []apiKey{testAPIKey("viewer", "viewer-key", RoleViewer)}
+
+	tests := []struct {
+		name       string
+		inPostgres bool
+		key        string
+		wantStatus int // 0 when the request is authorized
+	}{
+		{name: "missing credentials", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", key: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "missing permission", key: "viewer-key", wantStatus: http.StatusForbidden},
+		{name: "failed api_keys lookup is a server fault", inPostgres: true, key: "guess", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useAPIKeys(t, keys, test.inPostgres)
+
+			r := httptest.NewRequest(http.MethodGet, "/loiter", nil)
+			if test.key != "" {
+				r.Header.Set("X-API-Key", test.key)
+			}
+
+			_, denied := authorize(httptest.NewRecorder(), r, MoveServo)
+			status := 0
+			if denied != nil {
+				status = denied.Status()
+			}
+			if status != test.wantStatus {
+				t.Errorf("status = %d want %d", status, test.wantStatus)
+			}
+		})
+	}
+}
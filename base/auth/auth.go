@@ -0,0 +1,95 @@
+// File: `Authentication Implementation File` base/auth/auth.go
+package PhoeniciaDigitalAuth
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// The identity a request was authenticated as
+type Principal struct {
+	Subject string   `json:"subject"` // the API key name or the JWT `sub` claim
+	Method  string   `json:"method"`  // api_key | jwt
+	Roles   []string `json:"roles"`   // viewer | operator | admin
+}
+
+type principalKey struct{}
+
+// Returns the Principal the request was authenticated as | false on routes without authentication
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+var (
+	errMissingCredentials = errors.New("missing credentials | send an `Authorization: Bearer <token>` or `X-API-Key` header")
+	errInvalidCredentials = errors.New("invalid credentials")
+
+	// The credentials could not be checked (ex: the api_keys table is unreachable) | a server fault answered with 503
+	errVerificationUnavailable = errors.New("credentials cannot be verified right now | try again later")
+)
+
+// Authentication is only enforced when AUTH_ENABLED=true so development setups keep working as before
+var enabled bool
+
+// Read the credentials of the request | Authorization: Bearer <token> or X-API-Key: <key>
+// Websocket handshakes can not set headers from a browser so they may also use ?token=<token>
+func credentials(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if scheme, token, found := strings.Cut(header, " "); found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("token")
+	}
+
+	return ""
+}
+
+// Authenticate the request with an API key or a JWT | tokens with two dots are treated as JWTs
+func Authenticate(r *http.Request) (Principal, error) {
+	token := credentials(r)
+	if token == "" {
+		return Principal{}, errMissingCredentials
+	}
+
+	if strings.Count(token, ".") == 2 {
+		return verifyJWT(token)
+	}
+	return verifyAPIKey(r.Context(), token)
+}
+
+func unauthorized(w http.ResponseWriter, err error) PhoeniciaDigitalUtils.ApiError {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="`+PhoeniciaDigitalConfig.Config().Project_Name+`"`)
+	return PhoeniciaDigitalUtils.ApiError{Code: http.StatusUnauthorized, Quote: err.Error()}
+}
+
+// Initializes the authentication from the AUTH_* values in ./config/.env
+func init() {
+	enabled = PhoeniciaDigitalConfig.Config().Auth.Auth_enabled
+
+	if err := loadAPIKeys(); err != nil {
+		PhoeniciaDigitalUtils.Fatal("Invalid AUTH_API_KEYS | Change in ./config/.env", "error", err)
+	}
+
+	if err := loadJWTKeys(); err != nil {
+		PhoeniciaDigitalUtils.Fatal("Invalid AUTH_JWT_* settings | Change in ./config/.env", "error", err)
+	}
+
+	if !enabled {
+		PhoeniciaDigitalUtils.Logger.Warn("Authentication is DISABLED | Anyone who can reach the API can move the servo | Set AUTH_ENABLED=true in ./config/.env")
+		return
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Authentication enabled", "api_keys", len(apiKeys), "api_keys_postgres", apiKeysInPostgres, "jwt_hs256", jwtHS256Secret != nil, "jwt_rs256", jwtRS256Key != nil)
+}
@@ -0,0 +1,183 @@
+// File: `JWT Authentication File` base/auth/jwt.go
+package PhoeniciaDigitalAuth
+
+import (
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Leeway given to exp & nbf for clocks that drift between the issuer & the device
+const jwtClockSkew time.Duration = 30 * time.Second
+
+var (
+	jwtHS256Secret []byte
+	jwtRS256Key    *rsa.PublicKey
+	jwtIssuer      string
+	jwtAudience    string
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// The registered claims checked by the API | aud may be a single string or a list
+// The roles are carried in the `roles` claim (a list) or the `role` claim (a single role)
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Roles     []string        `json:"roles"`
+	Role      string          `json:"role"`
+}
+
+// Load the HS256 secret & the RS256 public key (PEM file path) from AUTH_JWT_*
+func loadJWTKeys() error {
+	settings := PhoeniciaDigitalConfig.Config().Auth
+
+	if settings.Auth_jwt_hs256_secret != "" {
+		jwtHS256Secret = []byte(settings.Auth_jwt_hs256_secret)
+	}
+
+	if settings.Auth_jwt_rs256_public_key != "" {
+		data, err := os.ReadFile(settings.Auth_jwt_rs256_public_key)
+		if err != nil {
+			return fmt.Errorf("failed to read AUTH_JWT_RS256_PUBLIC_KEY: %w", err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return errors.New("AUTH_JWT_RS256_PUBLIC_KEY is not a PEM file")
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse AUTH_JWT_RS256_PUBLIC_KEY: %w", err)
+		}
+
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("AUTH_JWT_RS256_PUBLIC_KEY is not an RSA public key")
+		}
+		jwtRS256Key = rsaKey
+	}
+
+	jwtIssuer = settings.Auth_jwt_issuer
+	jwtAudience = settings.Auth_jwt_audience
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Verify the signature & the registered claims of a compact JWT | only HS256 & RS256 are accepted
+// so a token can never pick `none` or swap algorithms on the API
+func verifyJWT(token string) (Principal, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return Principal{}, errInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(segments[0], &header); err != nil {
+		return Principal{}, errInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return Principal{}, errInvalidCredentials
+	}
+
+	signed := []byte(segments[0] + "." + segments[1])
+	digest := sha256.Sum256(signed)
+
+	switch header.Alg {
+	case "HS256":
+		if jwtHS256Secret == nil {
+			return Principal{}, errInvalidCredentials
+		}
+		mac := hmac.New(sha256.New, jwtHS256Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return Principal{}, errInvalidCredentials
+		}
+	case "RS256":
+		if jwtRS256Key == nil {
+			return Principal{}, errInvalidCredentials
+		}
+		if err := rsa.VerifyPKCS1v15(jwtRS256Key, crypto.SHA256, digest[:], signature); err != nil {
+			return Principal{}, errInvalidCredentials
+		}
+	default:
+		return Principal{}, errInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(segments[1], &claims); err != nil {
+		return Principal{}, errInvalidCredentials
+	}
+
+	now := time.Now()
+	// Tokens without exp would be valid forever
+	if claims.ExpiresAt == nil {
+		return Principal{}, errors.New("token has no exp claim")
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtClockSkew)) {
+		return Principal{}, errors.New("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtClockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return Principal{}, errors.New("token not valid yet")
+	}
+	if jwtIssuer != "" && claims.Issuer != jwtIssuer {
+		return Principal{}, errInvalidCredentials
+	}
+	if jwtAudience != "" && !claims.hasAudience(jwtAudience) {
+		return Principal{}, errInvalidCredentials
+	}
+	if claims.Subject == "" {
+		return Principal{}, errInvalidCredentials
+	}
+
+	roles, err := parseRoles(strings.Join(append(claims.Roles, claims.Role), ","), ",")
+	if err != nil || len(roles) == 0 {
+		return Principal{}, errors.New("token carries no valid role")
+	}
+
+	return Principal{Subject: claims.Subject, Method: "jwt", Roles: roles}, nil
+}
+
+func (c jwtClaims) hasAudience(audience string) bool {
+	var single string
+	if err := json.Unmarshal(c.Audience, &single); err == nil {
+		return single == audience
+	}
+
+	var list []string
+	if err := json.Unmarshal(c.Audience, &list); err == nil {
+		for _, aud := range list {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,357 @@
+// File: `JWT Authentication Tests File` base/auth/jwt_test.go
+package PhoeniciaDigitalAuth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testHS256Secret string = "test-secret"
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// A compact JWT with the header & claims given | sign returns the signature of the first two segments
+func testToken(t *testing.T, header map[string]any, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func signHS256(secret []byte) func(signed []byte) []byte {
+	return func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil)
+	}
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey) func(signed []byte) []byte {
+	return func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+}
+
+// Valid claims with the changes given applied | a nil value removes the claim
+func testClaims(changes map[string]any) map[string]any {
+	claims := map[string]any{
+		"sub":   "dashboard",
+		"roles": []string{"operator"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+// Replace the verifier keys & expectations for a test | restored once the test ends
+func useJWTSettings(t *testing.T, secret []byte, key *rsa.PublicKey, issuer string, audience string) {
+	t.Helper()
+	previousSecret, previousKey, previousIssuer, previousAudience := jwtHS256Secret, jwtRS256Key, jwtIssuer, jwtAudience
+	jwtHS256Secret, jwtRS256Key, jwtIssuer, jwtAudience = secret, key, issuer, audience
+	t.Cleanup(func() {
+		jwtHS256Secret, jwtRS256Key, jwtIssuer, jwtAudience = previousSecret, previousKey, previousIssuer, previousAudience
+	})
+}
+
+func TestVerifyJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	rs256 := map[string]any{"alg": "RS256", "typ": "JWT"}
+	secret := []byte(testHS256Secret)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		secret   []byte
+		rsa      bool // verify RS256 with the public key of rsaKey
+		issuer   string
+		audience string
+		token    func(t *testing.T) string
+		want     Principal
+		wantErr  string
+	}{
+		// Valid tokens
+		{
+			name:   "valid HS256 token",
+			secret: secret,
+			token:  func(t *testing.T) string { return testToken(t, hs256, testClaims(nil), signHS256(secret)) },
+			want:   Principal{Subject: "dashboard", Method: "jwt", Roles: []string{"operator"}},
+		},
+		{
+			name:  "valid RS256 token",
+			rsa:   true,
+			token: func(t *testing.T) string { return testToken(t, rs256, testClaims(nil), signRS256(t, rsaKey)) },
+			want:  Principal{Subject: "dashboard", Method: "jwt", Roles: []string{"operator"}},
+		},
+		{
+			name:   "single role claim",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"roles": nil, "role": "admin"}), signHS256(secret))
+			},
+			want: Principal{Subject: "dashboard", Method: "jwt", Roles: []string{"admin"}},
+		},
+		{
+			name:   "expired within the clock skew",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"exp": now.Add(-jwtClockSkew / 2).Unix()}), signHS256(secret))
+			},
+			want: Principal{Subject: "dashboard", Method: "jwt", Roles: []string{"operator"}},
+		},
+		{
+			name:     "issuer & audience list match",
+			secret:   secret,
+			issuer:   "phoenicia",
+			audience: "pi",
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"iss": "phoenicia", "aud": []string{"other", "pi"}}), signHS256(secret))
+			},
+			want: Principal{Subject: "dashboard", Method: "jwt", Roles: []string{"operator"}},
+		},
+
+		// Algorithm confusion
+		{
+			name:   "alg none is refused",
+			secret: secret,
+			rsa:    true,
+			token: func(t *testing.T) string {
+				return testToken(t, map[string]any{"alg": "none"}, testClaims(nil), func([]byte) []byte { return nil })
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "alg names are case sensitive",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, map[string]any{"alg": "hs256"}, testClaims(nil), signHS256(secret))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name: "HS256 signed with the RS256 public key is refused without an HS256 secret",
+			rsa:  true,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(nil), signHS256(publicPEM))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "HS256 signed with the RS256 public key is refused with an HS256 secret",
+			secret: secret,
+			rsa:    true,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(nil), signHS256(publicPEM))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:    "RS256 header with an HMAC signature is refused",
+			secret:  secret,
+			rsa:     true,
+			token:   func(t *testing.T) string { return testToken(t, rs256, testClaims(nil), signHS256(secret)) },
+			wantErr: "invalid credentials",
+		},
+		{
+			name:    "RS256 is refused when only HS256 is configured",
+			secret:  secret,
+			token:   func(t *testing.T) string { return testToken(t, rs256, testClaims(nil), signRS256(t, rsaKey)) },
+			wantErr: "invalid credentials",
+		},
+
+		// Bad signatures
+		{
+			name:   "HS256 signed with another secret",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(nil), signHS256([]byte("other-secret")))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:    "RS256 signed with another key",
+			rsa:     true,
+			token:   func(t *testing.T) string { return testToken(t, rs256, testClaims(nil), signRS256(t, otherKey)) },
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "claims changed after signing",
+			secret: secret,
+			token: func(t *testing.T) string {
+				segments := strings.Split(testToken(t, hs256, testClaims(nil), signHS256(secret)), ".")
+				segments[1] = encodeSegment(t, testClaims(map[string]any{"roles": []string{"admin"}}))
+				return strings.Join(segments, ".")
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "signature that is not base64url",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return encodeSegment(t, hs256) + "." + encodeSegment(t, testClaims(nil)) + ".***"
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:    "header that is not JSON",
+			secret:  secret,
+			token:   func(t *testing.T) string { return "bm90LWpzb24." + encodeSegment(t, testClaims(nil)) + ".c2ln" },
+			wantErr: "invalid credentials",
+		},
+		{
+			name:    "too few segments",
+			secret:  secret,
+			token:   func(t *testing.T) string { return encodeSegment(t, hs256) + "." + encodeSegment(t, testClaims(nil)) },
+			wantErr: "invalid credentials",
+		},
+
+		// Expiry & validity window
+		{
+			name:   "expired past the clock skew",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"exp": now.Add(-2 * jwtClockSkew).Unix()}), signHS256(secret))
+			},
+			wantErr: "token expired",
+		},
+		{
+			name:   "missing exp",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"exp": nil}), signHS256(secret))
+			},
+			wantErr: "token has no exp claim",
+		},
+		{
+			name:   "not valid yet",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"nbf": now.Add(2 * jwtClockSkew).Unix()}), signHS256(secret))
+			},
+			wantErr: "token not valid yet",
+		},
+
+		// Missing or wrong claims
+		{
+			name:   "missing sub",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"sub": nil}), signHS256(secret))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "missing roles",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"roles": nil}), signHS256(secret))
+			},
+			wantErr: "token carries no valid role",
+		},
+		{
+			name:   "unknown role",
+			secret: secret,
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"roles": []string{"root"}}), signHS256(secret))
+			},
+			wantErr: "token carries no valid role",
+		},
+		{
+			name:    "missing issuer",
+			secret:  secret,
+			issuer:  "phoenicia",
+			token:   func(t *testing.T) string { return testToken(t, hs256, testClaims(nil), signHS256(secret)) },
+			wantErr: "invalid credentials",
+		},
+		{
+			name:   "wrong issuer",
+			secret: secret,
+			issuer: "phoenicia",
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"iss": "someone-else"}), signHS256(secret))
+			},
+			wantErr: "invalid credentials",
+		},
+		{
+			name:     "missing audience",
+			secret:   secret,
+			audience: "pi",
+			token:    func(t *testing.T) string { return testToken(t, hs256, testClaims(nil), signHS256(secret)) },
+			wantErr:  "invalid credentials",
+		},
+		{
+			name:     "wrong audience",
+			secret:   secret,
+			audience: "pi",
+			token: func(t *testing.T) string {
+				return testToken(t, hs256, testClaims(map[string]any{"aud": "laptop"}), signHS256(secret))
+			},
+			wantErr: "invalid credentials",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var key *rsa.PublicKey
+			if test.rsa {
+				key = &rsaKey.PublicKey
+			}
+			useJWTSettings(t, test.secret, key, test.issuer, test.audience)
+
+			got, err := verifyJWT(test.token(t))
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("error = %v want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("principal = %+v want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// File: `Roles & Permissions File` base/auth/roles.go
+package PhoeniciaDigitalAuth
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// A Permission is what a route requires | Roles are only a named set of permissions
+type Permission string
+
+const (
+	ReadSensor       Permission = "sensor:read"       // open the /sensor stream
+	ReadState        Permission = "state:read"        // list & replay recordings, export readings & scans
+	MoveServo        Permission = "servo:move"        // loiter & rotate
+	RecordSessions   Permission = "recordings:write"  // start & stop recordings
+	WriteCalibration Permission = "calibration:write" // apply a servo trim & sensor scale to the running device
+	ReadConfig       Permission = "config:read"       // see the effective configuration
+	ManageMigrations Permission = "migrations:write"  // see, apply & revert the database migrations
+)
+
+// Permissions that are never granted while AUTH_ENABLED is false | admin routes answer 403 instead of serving
+// anonymous clients (the CLI is the way to run them without authentication ex: `main check-config`, `main calibrate`
+// & `main migrate`)
+var adminOnly = map[Permission]bool{
+	WriteCalibration: true,
+	ReadConfig:       true,
+	ManageMigrations: true,
+}
+
+// The roles & what they are allowed to do | every role includes the permissions of the one below it
+const (
+	RoleViewer   string = "viewer"
+	RoleOperator string = "operator"
+	RoleAdmin    string = "admin"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleViewer:   {ReadSensor, ReadState},
+	RoleOperator: {ReadSensor, ReadState, MoveServo, RecordSessions},
+	RoleAdmin:    {ReadSensor, ReadState, MoveServo, RecordSessions, WriteCalibration, ReadConfig, ManageMigrations},
+}
+
+// Parse a role list such as `operator` or `viewer|admin` | unknown roles are an error so typos do not silently lock anyone out
+func parseRoles(value string, separator string) ([]string, error) {
+	roles := []string{}
+	for _, role := range strings.Split(value, separator) {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role == "" {
+			continue
+		}
+		if _, known := rolePermissions[role]; !known {
+			return nil, fmt.Errorf("unknown role: %s | must be one of viewer, operator, admin", role)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// Reports if any of the roles of the Principal grants the permission
+func (p Principal) Can(permission Permission) bool {
+	for _, role := range p.Roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticate the request & check the permission | returns the ApiError 401, 403 or 503 (credentials that could
+// not be checked) to answer with
+func authorize(w http.ResponseWriter, r *http.Request, permission Permission) (*http.Request, *PhoeniciaDigitalUtils.ApiError) {
+	if !enabled {
+		if adminOnly[permission] {
+			return r, &PhoeniciaDigitalUtils.ApiError{Code: http.StatusForbidden, Quote: fmt.Sprintf("%s requires AUTH_ENABLED=true", permission)}
+		}
+		return r, nil
+	}
+
+	principal, err := Authenticate(r)
+	if errors.Is(err, errVerificationUnavailable) {
+		// The cause is only logged | the client is told to retry without the database details
+		PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Failed to verify credentials", "error", err)
+		return r, &PhoeniciaDigitalUtils.ApiError{Code: http.StatusServiceUnavailable, Quote: errVerificationUnavailable.Error()}
+	}
+	if err != nil {
+		response := unauthorized(w, err)
+		return r, &response
+	}
+
+	if !principal.Can(permission) {
+		return r, &PhoeniciaDigitalUtils.ApiError{Code: http.StatusForbidden, Quote: fmt.Sprintf("missing permission: %s", permission)}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)), nil
+}
+
+// Permit is the middleware attached to a route at registration | the request must be authenticated (401)
+// & one of its roles must grant the permission (403) ex: handle("GET /loiter", source.HandleLoiter, Permit(MoveServo))
+func Permit(permission Permission) PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware {
+	return func(next PhoeniciaDigitalUtils.PhoeniciaDigitalHandler) PhoeniciaDigitalUtils.PhoeniciaDigitalHandler {
+		return func(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+			r, denied := authorize(w, r, permission)
+			if denied != nil {
+				return *denied
+			}
+			return next(w, r)
+		}
+	}
+}
+
+// PermitHTTP protects plain http.HandlerFunc routes such as the websocket handshakes the same way as Permit
+func PermitHTTP(permission Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r, denied := authorize(w, r, permission)
+		if denied != nil {
+			PhoeniciaDigitalUtils.Logger.WarnContext(r.Context(), "API call returned an error", "method", r.Method, "path", r.URL.Path, "status", denied.Status(), "response", denied.Log())
+			PhoeniciaDigitalUtils.SendJSON(w, denied.Status(), *denied)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,224 @@
+// File: `Command Line File` base/cli/cli.go
+package PhoeniciaDigitalCLI
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalServer "Phoenicia-Digital-Base-API/base/server"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"Phoenicia-Digital-Base-API/source"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// A subcommand of the binary ex: ./main scan --format json
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
+
+// Filled in init since the help command lists them
+var commands []command
+
+// Returned when the arguments are wrong | the usage is already printed so only the exit code is left
+var errUsage = errors.New("usage")
+
+// Run the subcommand named by the first argument (serve when there is none) & return the exit code
+// The config flags (--port, --log-level & --config) are accepted before & after the subcommand
+func Run(args []string) int {
+	defer PhoeniciaDigitalDatabase.Close()
+
+	global := newFlagSet("main")
+	global.Usage = func() { usage(global.Output()) }
+	if err := global.Parse(args); err != nil {
+		return exitCode(err)
+	}
+
+	name, args := "serve", global.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return exitCode(cmd.run(args))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+	usage(os.Stderr)
+	return 2
+}
+
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: main [--port PORT] [--log-level LEVEL] [--config FILE] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(table, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	table.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `main <command> -h` for the arguments of a command")
+}
+
+// A FlagSet accepting the config flags | they are already applied since the config is loaded before main runs
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	PhoeniciaDigitalConfig.RegisterFlags(fs)
+	return fs
+}
+
+// Passed to parse by commands checking their own positional arguments
+const anyArguments int = -1
+
+// Parse the arguments of a command | errUsage when they are wrong & flag.ErrHelp on -h
+func parse(fs *flag.FlagSet, args []string, positional int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if positional != anyArguments && fs.NArg() != positional {
+		fmt.Fprintf(fs.Output(), "%s: expected %d argument(s) got: %s\n", fs.Name(), positional, strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// The --format flag of the commands printing results | table (for people) or json (for scripts)
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "table", "output format: table | json")
+}
+
+func checkFormat(fs *flag.FlagSet, format string) error {
+	if format != "table" && format != "json" {
+		fmt.Fprintf(fs.Output(), "%s: --format must be table or json got: %s\n", fs.Name(), format)
+		return errUsage
+	}
+	return nil
+}
+
+func printJSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// Serve the API | the default command so `./main` keeps starting the server
+func serve(args []string) error {
+	fs := newFlagSet("serve")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	source.HCSR04.InitializeUltrasonicSensor()
+	source.ServoMotor.InitializeServoMotor()
+	PhoeniciaDigitalServer.StartServer()
+	return nil
+}
+
+// Print the effective config | reaching this means it is valid since an invalid config stops the binary while it is
+// loaded listing every invalid value
+func checkConfig(args []string) error {
+	fs := newFlagSet("check-config")
+	format := formatFlag(fs)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkFormat(fs, *format); err != nil {
+		return err
+	}
+
+	description := PhoeniciaDigitalConfig.Describe()
+	if *format == "json" {
+		return printJSON(description)
+	}
+
+	values := map[string]PhoeniciaDigitalConfig.Value{}
+	for name, described := range description {
+		switch typed := described.(type) {
+		case PhoeniciaDigitalConfig.Value:
+			values[name] = typed
+		case map[string]PhoeniciaDigitalConfig.Value:
+			for field, value := range typed {
+				values[name+"."+field] = value
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := []string{}
+	for _, file := range PhoeniciaDigitalConfig.Files() {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		files = append(files, "none")
+	}
+	fmt.Printf("Config is valid | files read: %s\n\n", strings.Join(files, " & "))
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tENV\tSOURCE\tVALUE")
+	for _, name := range names {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", name, values[name].Env, values[name].Source, formatValue(values[name].Value))
+	}
+	return table.Flush()
+}
+
+// A config value the way it is written in the environment ex: lists are comma separated
+func formatValue(value any) string {
+	switch typed := value.(type) {
+	case []string:
+		return strings.Join(typed, ",")
+	case map[string]string:
+		entries := make([]string, 0, len(typed))
+		for key, entry := range typed {
+			entries = append(entries, key+"="+entry)
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ",")
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func init() {
+	commands = []command{
+		{name: "serve", summary: "serve the API (the default command)", run: serve},
+		{name: "check-config", args: "[--format table|json]", summary: "validate & print the effective config with the source of every value", run: checkConfig},
+		{name: "calibrate", args: "servo|sensor", summary: "calibrate the servo or the sensor interactively & print the values to set", run: calibrate},
+		{name: "scan", args: "[--format table|json]", summary: "sweep the servo once measuring the distance at every step", run: scan},
+		{name: "measure", args: "[-n N] [--interval D] [--format table|json]", summary: "take N measurements & print their stats", run: measure},
+		{name: "migrate", args: "[--format table|json] [up | down [N] | to VERSION | status]", summary: "apply or revert the migrations of sql/migrations (up by default)", run: migrate},
+		{name: "help", summary: "print this help", run: func([]string) error { usage(os.Stdout); return nil }},
+	}
+}
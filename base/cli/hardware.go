@@ -0,0 +1,171 @@
+// File: `Hardware Commands File` base/cli/hardware.go
+package PhoeniciaDigitalCLI
+
+import (
+	"Phoenicia-Digital-Base-API/source"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Calibrate the servo (SERVO_TRIM) or the sensor (SENSOR_SCALE) answering the prompts on the terminal
+func calibrate(args []string) error {
+	fs := newFlagSet("calibrate")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	switch fs.Arg(0) {
+	case "servo":
+		source.ServoMotor.InitializeServoMotor()
+		trim, err := source.ServoMotor.Calibrate(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nSet in ./config/.env or the config file:\nSERVO_TRIM=%g\n", trim)
+	case "sensor":
+		source.HCSR04.InitializeUltrasonicSensor()
+		scale, err := source.HCSR04.Calibrate(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nSet in ./config/.env or the config file:\nSENSOR_SCALE=%.6f\n", scale)
+	default:
+		fmt.Fprintf(fs.Output(), "calibrate: expected servo or sensor got: %s\n", fs.Arg(0))
+		return errUsage
+	}
+	return nil
+}
+
+// Sweep the servo once | the frames are stored for GET /export/scans when Postgres is enabled
+func scan(args []string) error {
+	fs := newFlagSet("scan")
+	format := formatFlag(fs)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkFormat(fs, *format); err != nil {
+		return err
+	}
+
+	source.HCSR04.InitializeUltrasonicSensor()
+	source.ServoMotor.InitializeServoMotor()
+
+	frames, err := source.ServoMotor.Scan(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		return printJSON(frames)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "DEGREE\tDISTANCE (cm)\tSTATUS\t")
+	for _, frame := range frames {
+		fmt.Fprintf(table, "%.0f\t%.2f\t%s\t\n", frame.Degree, frame.Distance, frame.Status)
+	}
+	return table.Flush()
+}
+
+// Stats of the successful measurements of the measure command
+type measurementStats struct {
+	Count  int     `json:"count"`
+	Failed int     `json:"failed"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"`
+}
+
+func newMeasurementStats(readings []source.SensorData) measurementStats {
+	distances := []float64{}
+	for _, reading := range readings {
+		if reading.Status == "Success" {
+			distances = append(distances, reading.Distance)
+		}
+	}
+
+	stats := measurementStats{Count: len(distances), Failed: len(readings) - len(distances)}
+	if len(distances) == 0 {
+		return stats
+	}
+
+	sort.Float64s(distances)
+	stats.Min, stats.Max = distances[0], distances[len(distances)-1]
+
+	if middle := len(distances) / 2; len(distances)%2 == 0 {
+		stats.Median = (distances[middle-1] + distances[middle]) / 2
+	} else {
+		stats.Median = distances[middle]
+	}
+
+	for _, distance := range distances {
+		stats.Mean += distance
+	}
+	stats.Mean /= float64(len(distances))
+
+	for _, distance := range distances {
+		stats.StdDev += (distance - stats.Mean) * (distance - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(stats.StdDev / float64(len(distances)))
+
+	return stats
+}
+
+// Take N measurements with the sensor & print them with their stats
+func measure(args []string) error {
+	fs := newFlagSet("measure")
+	count := fs.Int("n", 10, "number of measurements")
+	interval := fs.Duration("interval", 100*time.Millisecond, "time between measurements | the HC-SR04 needs at least 60ms")
+	format := formatFlag(fs)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkFormat(fs, *format); err != nil {
+		return err
+	}
+	if *count < 1 || *interval < 60*time.Millisecond {
+		fmt.Fprintln(fs.Output(), "measure: -n must be >= 1 & --interval >= 60ms")
+		return errUsage
+	}
+
+	source.HCSR04.InitializeUltrasonicSensor()
+
+	readings := make([]source.SensorData, 0, *count)
+	for i := 0; i < *count; i++ {
+		if i > 0 {
+			time.Sleep(*interval)
+		}
+		reading := source.HCSR04.Measure()
+		readings = append(readings, reading)
+		if *format == "table" {
+			fmt.Printf("#%-4d %10.2f cm  %s\n", i+1, reading.Distance, reading.Status)
+		}
+	}
+
+	stats := newMeasurementStats(readings)
+	if *format == "json" {
+		return printJSON(struct {
+			Readings []source.SensorData `json:"readings"`
+			Stats    measurementStats    `json:"stats"`
+		}{readings, stats})
+	}
+
+	fmt.Println()
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "count\t%d\t(%d failed)\n", stats.Count, stats.Failed)
+	if stats.Count > 0 {
+		fmt.Fprintf(table, "min\t%.2f cm\n", stats.Min)
+		fmt.Fprintf(table, "max\t%.2f cm\n", stats.Max)
+		fmt.Fprintf(table, "mean\t%.2f cm\n", stats.Mean)
+		fmt.Fprintf(table, "median\t%.2f cm\n", stats.Median)
+		fmt.Fprintf(table, "stddev\t%.2f cm\n", stats.StdDev)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,98 @@
+// File: `Migration Commands File` base/cli/migrate.go
+package PhoeniciaDigitalCLI
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Manage the migrations of sql/migrations | migrate [up | down [N] | to VERSION | status] (up when none is given)
+func migrate(args []string) error {
+	fs := newFlagSet("migrate")
+	format := formatFlag(fs)
+	if err := parse(fs, args, anyArguments); err != nil {
+		return err
+	}
+	if err := checkFormat(fs, *format); err != nil {
+		return err
+	}
+
+	action, rest := "up", fs.Args()
+	if len(rest) > 0 {
+		action, rest = rest[0], rest[1:]
+	}
+
+	ctx := context.Background()
+	var changed []PhoeniciaDigitalDatabase.Migration
+	var err error
+	switch {
+	case action == "up" && len(rest) == 0:
+		changed, err = PhoeniciaDigitalDatabase.MigrateUp(ctx)
+	case action == "down" && len(rest) <= 1:
+		steps := 1
+		if len(rest) == 1 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil {
+				return fmt.Errorf("migrate down: the number of migrations must be a whole number got: %s", rest[0])
+			}
+		}
+		changed, err = PhoeniciaDigitalDatabase.MigrateDown(ctx, steps)
+	case action == "to" && len(rest) == 1:
+		version, parseErr := strconv.ParseInt(rest[0], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("migrate to: the version must be a whole number got: %s", rest[0])
+		}
+		changed, err = PhoeniciaDigitalDatabase.MigrateTo(ctx, version)
+	case action == "status" && len(rest) == 0:
+		return migrationStatus(ctx, *format)
+	default:
+		fmt.Fprintln(fs.Output(), "Usage: main migrate [--format table|json] [up | down [N] | to VERSION | status]")
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		return printJSON(changed)
+	}
+	if len(changed) == 0 {
+		fmt.Println("Nothing to migrate | the database is already at the requested version")
+	}
+	for _, migration := range changed {
+		verb := "Reverted"
+		if migration.Applied {
+			verb = "Applied"
+		}
+		fmt.Printf("%s %04d_%s\n", verb, migration.Version, migration.Name)
+	}
+	return nil
+}
+
+func migrationStatus(ctx context.Context, format string) error {
+	status, err := PhoeniciaDigitalDatabase.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		return printJSON(status)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, migration := range status {
+		state, appliedAt := "pending", ""
+		if migration.Applied {
+			state, appliedAt = "applied", migration.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if migration.Missing {
+			state = "applied (files missing)"
+		}
+		fmt.Fprintf(table, "%04d\t%s\t%s\t%s\n", migration.Version, migration.Name, state, appliedAt)
+	}
+	return table.Flush()
+}
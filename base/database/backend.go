@@ -0,0 +1,151 @@
+// File: `Database Backends File` base/database/backend.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Every database is opt-in (POSTGRES_ENABLED, MONGODB_ENABLED & REDIS_ENABLED) & only connects the first time it is used
+// The accessors (PostgresDB, MongoDatabase, RedisClient, ...) return a *DisabledError for a database that is not
+// enabled instead of a nil pointer | Check for it with errors.Is(err, PhoeniciaDigitalDatabase.ErrDisabled)
+
+var ErrDisabled = errors.New("database backend disabled")
+
+type DisabledError struct {
+	Backend string // Postgres | MongoDB | Redis
+	Setting string // the config value enabling it ex: POSTGRES_ENABLED
+}
+
+func (e *DisabledError) Error() string {
+	return fmt.Sprintf("%s is disabled | set %s=true in ./config/.env to use it", e.Backend, e.Setting)
+}
+
+func (e *DisabledError) Is(target error) bool {
+	return target == ErrDisabled
+}
+
+const (
+	connectTimeout time.Duration = 5 * time.Second // given to every connection attempt
+	connectBackoff time.Duration = 5 * time.Second // failed attempts are not repeated for this long so callers fail fast
+)
+
+// A database connected on first use | T is the client of the database ex: *sql.DB
+type backend[T any] struct {
+	id         string // the component name in /readyz & the metrics ex: postgres
+	name       string
+	setting    string
+	enabled    bool
+	connect    func(ctx context.Context) (T, error)
+	ping       func(ctx context.Context, client T) error
+	disconnect func(client T) error
+
+	mu        sync.Mutex
+	client    T
+	connected bool
+	available bool  // connected & the last ping of the monitor succeeded
+	lastErr   error // why it is not available
+	retryAt   time.Time
+}
+
+// The backends as a list | every method of connection is implemented by backend[T] whatever its client is
+type connection interface {
+	waitConnected(ctx context.Context, retry retryPolicy) error
+	check(ctx context.Context)
+	status() Status
+	close() error
+}
+
+var backends = []connection{postgresBackend, mongoBackend, redisBackend}
+
+// A single connection attempt | the caller must hold b.mu
+func (b *backend[T]) connectLocked() error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	client, err := b.connect(ctx)
+	if err != nil {
+		b.lastErr = fmt.Errorf("failed to connect to %s: %w", b.name, err)
+		b.retryAt = time.Now().Add(connectBackoff)
+		return b.lastErr
+	}
+
+	b.client, b.connected, b.available, b.lastErr = client, true, true, nil
+	return nil
+}
+
+// The connected client | connects on the first call & after a failed attempt once connectBackoff passed
+// ctx only carries the request ID of the failure log | the attempt itself is bounded by connectTimeout
+func (b *backend[T]) get(ctx context.Context) (T, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var none T
+	if !b.enabled {
+		return none, &DisabledError{Backend: b.name, Setting: b.setting}
+	}
+	if b.connected {
+		return b.client, nil
+	}
+	if time.Now().Before(b.retryAt) {
+		return none, b.lastErr
+	}
+
+	if err := b.connectLocked(); err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Failed to connect to database | Verify its config values ./config/.env", "backend", b.name, "error", err)
+		return none, err
+	}
+	return b.client, nil
+}
+
+// The client if it is already connected | used where a connection must not be started ex: metrics scrapes
+func (b *backend[T]) current() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.client, b.connected
+}
+
+func (b *backend[T]) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.connected {
+		return nil
+	}
+
+	var none T
+	err := b.disconnect(b.client)
+	b.client, b.connected, b.available = none, false, false
+	if err != nil {
+		return fmt.Errorf("failed to close %s: %w", b.name, err)
+	}
+	return nil
+}
+
+// Close the connection of every connected database | called once the command run by main returns
+func Close() {
+	for _, backend := range backends {
+		if err := backend.close(); err != nil {
+			PhoeniciaDigitalUtils.Logger.Warn("Failed to close database connection", "error", err)
+		}
+	}
+}
+
+// Databases used to be implemented as soon as their values were filled in | warn when they still are but the
+// database is not enabled so upgrading configs do not silently lose it
+func init() {
+	config := PhoeniciaDigitalConfig.Config()
+
+	if !config.Postgres.Postgres_enabled && config.Postgres.Postgres_user != "" && config.Postgres.Postgres_db != "" {
+		PhoeniciaDigitalUtils.Logger.Warn("POSTGRES_USER & POSTGRES_DB are set but Postgres is disabled | Set POSTGRES_ENABLED=true in ./config/.env to use it")
+	}
+
+	if !config.Mongo.Mongo_enabled && config.Mongo.Mongo_db != "" {
+		PhoeniciaDigitalUtils.Logger.Warn("MONGODB_DATABASE is set but MongoDB is disabled | Set MONGODB_ENABLED=true in ./config/.env to use it")
+	}
+}
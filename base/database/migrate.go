@@ -0,0 +1,323 @@
+// File: `Postgres Migrations File` base/database/migrate.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Migrations are pairs of files in sql/migrations named <version>_<name>.up.sql & <version>_<name>.down.sql
+// ex: 0002_add_devices.up.sql | Every applied version is recorded in the schema_migrations table & every run holds a
+// Postgres advisory lock so two instances never migrate at the same time
+
+// The folder of the migrations inside the sql folder | read with Postgres.ReadSQL like every other .sql file
+const migrationsDir string = "migrations"
+
+// Key of the advisory lock held while migrating | any constant shared by every instance works
+const migrationLockKey int64 = 0x5044_4d49_4752 // "PDMIGR"
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.(up|down)\.sql$`)
+
+// A migration & whether it is applied to the database
+type Migration struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Missing   bool       `json:"missing,omitempty"` // applied to the database but its files are gone
+}
+
+// The up & down files of a version | names without .sql as Postgres.ReadSQL expects them
+type migrationFiles struct {
+	version  int64
+	name     string
+	up, down string
+}
+
+// Read the migrations of sql/migrations sorted by version | both files of a version are required
+func loadMigrations() ([]migrationFiles, error) {
+	entries, err := fs.ReadDir(sqlFiles, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s (%s): %w", migrationsDir, SQLSource(), err)
+	}
+
+	byVersion := map[int64]*migrationFiles{}
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("%s: the version must be a number >= 1", entry.Name())
+		}
+
+		files, ok := byVersion[version]
+		if !ok {
+			files = &migrationFiles{version: version, name: match[2]}
+			byVersion[version] = files
+		}
+		if files.name != match[2] {
+			return nil, fmt.Errorf("version %d is used by %s & %s", version, files.name, match[2])
+		}
+
+		name := migrationsDir + "/" + match[1] + "_" + match[2] + "." + match[3]
+		if match[3] == "up" {
+			files.up = name
+		} else {
+			files.down = name
+		}
+	}
+
+	migrations := make([]migrationFiles, 0, len(byVersion))
+	for _, files := range byVersion {
+		if files.up == "" || files.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an .up.sql & a .down.sql file", files.version, files.name)
+		}
+		migrations = append(migrations, *files)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Run fn on a single connection holding the migration lock | waits for the lock until ctx is done
+func withMigrationLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	db, err := postgresDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Advisory locks belong to the session so the lock, the migrations & the unlock must share one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("waiting for the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	query, err := readSQL(ctx, "create_schema_migrations")
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// A *sql.DB or the *sql.Conn holding the migration lock
+type migrationQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// The applied migrations keyed by version | none when schema_migrations does not exist yet
+func appliedMigrations(ctx context.Context, conn migrationQuerier) (map[int64]Migration, error) {
+	query, err := readSQL(ctx, "select_schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		// undefined_table | the first migration creates it
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+			return map[int64]Migration{}, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]Migration{}
+	for rows.Next() {
+		migration := Migration{Applied: true, AppliedAt: new(time.Time)}
+		if err := rows.Scan(&migration.Version, &migration.Name, migration.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[migration.Version] = migration
+	}
+	return applied, rows.Err()
+}
+
+// Every migration of sql/migrations & every version applied to the database without its files
+// Only reads schema_migrations so it neither waits for a running migration nor creates the table
+func MigrationStatus(ctx context.Context) ([]Migration, error) {
+	files, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := postgresDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := []Migration{}
+	for _, file := range files {
+		migration, ok := applied[file.version]
+		if !ok {
+			migration = Migration{Version: file.version, Name: file.name}
+		}
+		delete(applied, file.version)
+		status = append(status, migration)
+	}
+
+	for _, missing := range applied {
+		missing.Missing = true
+		status = append(status, missing)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// Apply every migration that is not applied yet | returns the ones applied by this call
+func MigrateUp(ctx context.Context) ([]Migration, error) {
+	return migrate(ctx, func(files []migrationFiles, applied map[int64]Migration) (int64, error) {
+		if len(files) == 0 {
+			return 0, nil
+		}
+		return files[len(files)-1].version, nil
+	})
+}
+
+// Revert the last `steps` applied migrations | returns the ones reverted by this call
+func MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("the number of migrations to revert must be >= 1 got: %d", steps)
+	}
+
+	return migrate(ctx, func(files []migrationFiles, applied map[int64]Migration) (int64, error) {
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		if steps >= len(versions) {
+			return 0, nil
+		}
+		return versions[steps], nil
+	})
+}
+
+// Apply or revert migrations until exactly the ones up to version are applied | 0 reverts every migration
+func MigrateTo(ctx context.Context, version int64) ([]Migration, error) {
+	return migrate(ctx, func(files []migrationFiles, applied map[int64]Migration) (int64, error) {
+		if version == 0 {
+			return 0, nil
+		}
+		for _, file := range files {
+			if file.version == version {
+				return version, nil
+			}
+		}
+		return 0, fmt.Errorf("no migration with version %d in sql/%s", version, migrationsDir)
+	})
+}
+
+// Bring the database to the version chosen by target while holding the lock | reverts the applied migrations above it
+// (newest first) then applies the missing ones up to it (oldest first) each in its own transaction
+func migrate(ctx context.Context, target func(files []migrationFiles, applied map[int64]Migration) (int64, error)) ([]Migration, error) {
+	files, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []Migration{}
+	err = withMigrationLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		version, err := target(files, applied)
+		if err != nil {
+			return err
+		}
+
+		byVersion := map[int64]migrationFiles{}
+		for _, file := range files {
+			byVersion[file.version] = file
+		}
+
+		reverting := []int64{}
+		for applied := range applied {
+			if applied > version {
+				reverting = append(reverting, applied)
+			}
+		}
+		sort.Slice(reverting, func(i, j int) bool { return reverting[i] > reverting[j] })
+
+		for _, applied := range reverting {
+			file, ok := byVersion[applied]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but its files are missing from sql/%s", applied, migrationsDir)
+			}
+			if err := runMigration(ctx, conn, file.down, "delete_schema_migration", file.version); err != nil {
+				return fmt.Errorf("reverting %d_%s: %w", file.version, file.name, err)
+			}
+			PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Reverted migration", "version", file.version, "name", file.name)
+			changed = append(changed, Migration{Version: file.version, Name: file.name})
+		}
+
+		for _, file := range files {
+			if _, done := applied[file.version]; done || file.version > version {
+				continue
+			}
+			if err := runMigration(ctx, conn, file.up, "insert_schema_migration", file.version, file.name); err != nil {
+				return fmt.Errorf("applying %d_%s: %w", file.version, file.name, err)
+			}
+			PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Applied migration", "version", file.version, "name", file.name)
+			changed = append(changed, Migration{Version: file.version, Name: file.name, Applied: true})
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// Run a migration file & record it in schema_migrations in one transaction so a failure leaves no trace of it
+func runMigration(ctx context.Context, conn *sql.Conn, fileName string, record string, args ...any) error {
+	migration, err := readSQL(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	recordQuery, err := readSQL(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, recordQuery, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -5,128 +5,122 @@ import (
 	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
 	"context"
 	"fmt"
-	"log"
-	"strconv"
+	"sync/atomic"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// Phoenicia Digital's MongoDB Database client struct that will be used through our applciations
-type mongodb struct {
-	Client *mongo.Client
-	DB     *mongo.Database
+// Connection pool statistics of the MongoDB Client kept up to date by the driver's pool events
+// The MongoDB driver does not expose pool statistics directly unlike database/sql & go-redis
+type MongoPoolStats struct {
+	OpenConnections  int64
+	InUseConnections int64
+	CheckOutFailures int64
 }
 
-// Implement a MongoDB Database Client to a global variable that will be used to manipulate and manage
-// Our MongoDB Database Client
-var Mongo *mongodb = implementMongoDB()
-
-// Function Used to Implement a MongoDB Client
-func implementMongoDB() *mongodb {
-	// mongoDB variable that will returned to the Global MongoDB Database Client Variable
-	var mongoDB *mongodb = &mongodb{}
-	// Set a conStr Variable That will be used as the connection string to our MongoDB Database
-	var conStr string
-
-	// Check That DB field are not empty in .env file since logically it cant be Empty -
-	// If implementing a MongoDB Database Connection
-	// If fields are empty the API Will consider it intentional since the Specific API will not use
-	// A MongoDB Database & Will return nil & Log The Warning that it will continue Running Withought
-	// A MongoDB Database Connection
-	if PhoeniciaDigitalConfig.Config.Mongo.Mongo_db != "" {
-		// Start Implementing the Connection String For MongoDB if database field not empty in .env
-		conStr = "mongodb://"
-	} else {
-		log.Printf("Continued with No MongoDB Database implementation! | In case expected a db connection MONGODB_DATABASE field REQUIRED ./config/.env\n")
-		PhoeniciaDigitalUtils.Log("Continued with No MongoDB Database implementation! | In case expected a db connection MONGODB_DATABASE field REQUIRED ./config/.env\n")
-		return nil
-	}
+var mongoPoolStats struct {
+	open, inUse, failed atomic.Int64
+}
 
-	// Check If MongoDB user field is no empty and add the username:password@ field to the connection string
-	// In case there is no username field filledout it will ignore this and continue to implement the
-	// Connection String disregarding the user and password
-	if PhoeniciaDigitalConfig.Config.Mongo.Mongo_user != "" {
-		conStr += fmt.Sprintf("%s:%s@", PhoeniciaDigitalConfig.Config.Mongo.Mongo_user, PhoeniciaDigitalConfig.Config.Mongo.Mongo_password)
+var mongoPoolMonitor *event.PoolMonitor = &event.PoolMonitor{
+	Event: func(e *event.PoolEvent) {
+		switch e.Type {
+		case event.ConnectionCreated:
+			mongoPoolStats.open.Add(1)
+		case event.ConnectionClosed:
+			mongoPoolStats.open.Add(-1)
+		case event.GetSucceeded:
+			mongoPoolStats.inUse.Add(1)
+		case event.ConnectionReturned:
+			mongoPoolStats.inUse.Add(-1)
+		case event.GetFailed:
+			mongoPoolStats.failed.Add(1)
+		}
+	},
+}
+
+// Returns the current connection pool statistics of the MongoDB Client | false until connected
+func MongoPoolStatistics() (MongoPoolStats, bool) {
+	if _, connected := mongoBackend.current(); !connected {
+		return MongoPoolStats{}, false
 	}
 
-	// Check if a spcific MongoDB host is provided and append that to the Connection String
-	if PhoeniciaDigitalConfig.Config.Mongo.Mongo_host != "" {
+	return MongoPoolStats{
+		OpenConnections:  mongoPoolStats.open.Load(),
+		InUseConnections: mongoPoolStats.inUse.Load(),
+		CheckOutFailures: mongoPoolStats.failed.Load(),
+	}, true
+}
 
-		conStr += PhoeniciaDigitalConfig.Config.Mongo.Mongo_host
-	} else if PhoeniciaDigitalConfig.Config.Project_Name != "" {
-		// If a Mongodb Host Is NOT Provided revert back to the Project Name
-		// Due to how our Dockerfile & docker-compose is set up
-		// the Mongodb Container Service is called {Project Name}-Mongodb
-		// then Get the Project Name and Append if to conStr wit the proper format
+var mongoBackend = &backend[*mongo.Client]{
+	id:         "mongo",
+	name:       "MongoDB",
+	setting:    "MONGODB_ENABLED",
+	enabled:    PhoeniciaDigitalConfig.Config().Mongo.Mongo_enabled,
+	connect:    connectMongoDB,
+	ping:       func(ctx context.Context, client *mongo.Client) error { return client.Ping(ctx, nil) },
+	disconnect: func(client *mongo.Client) error { return client.Disconnect(context.Background()) },
+}
 
-		conStr += fmt.Sprintf("%s-Mongodb", PhoeniciaDigitalConfig.Config.Project_Name)
-	} else {
-		// If a host is not provided for For the MongoDB Connection it will result in appending
-		// Phoenicia-Digital-Mongodb <MONGODB DEFAULT HOST> according to
-		// Dockerfile & docker-compose Setup to the Connection String
+// The MongoDB Client | connects on the first call
+// Returns a *DisabledError when MONGODB_ENABLED is false
+func MongoClient() (*mongo.Client, error) {
+	return mongoBackend.get(context.Background())
+}
 
-		conStr += "Phoenicia-Digital-Mongodb"
+// The MONGODB_DATABASE handle of the MongoDB Client | connects on the first call
+func MongoDatabase() (*mongo.Database, error) {
+	client, err := mongoBackend.get(context.Background())
+	if err != nil {
+		return nil, err
 	}
+	return client.Database(PhoeniciaDigitalConfig.Config().Mongo.Mongo_db), nil
+}
 
-	// Check if a specific Port is provided for MongoDB coonection
-	if PhoeniciaDigitalConfig.Config.Mongo.Mongo_port != "" {
-		// Make sure the provided Port is an actual Port <Intiger in range 0 <-> 65535>
-		if portNum, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Mongo.Mongo_port); err != nil {
-			// In case the provided port is not an integer the API will consider it an error
-			// And will Exist the process logging the error & issue
-			PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to implement MongoDB client | Invalid Port Number: %s", PhoeniciaDigitalConfig.Config.Mongo.Mongo_port))
-			log.Fatalf("Failed to implement MongoDB client | Invalid Port Number: %s", PhoeniciaDigitalConfig.Config.Mongo.Mongo_port)
-			return nil
-		} else {
-			if portNum < 0 || portNum > 65535 {
-				// In case the provided port is not in range 0 <-> 65535 the API will consider it an error
-				// And will Exist the process logging the error & issue
-				PhoeniciaDigitalUtils.Log(fmt.Sprintf("MONGODB PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalConfig.Config.Mongo.Mongo_port))
-				log.Fatalf("MONGODB PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalConfig.Config.Mongo.Mongo_port)
-				return nil
-			} else {
-				// If all is good and checked append the specific Port to the MongoDB Connection string
-				conStr += fmt.Sprintf(":%s", PhoeniciaDigitalConfig.Config.Mongo.Mongo_port)
-			}
-		}
-	} else {
-		// If no specific Port is provided it will append the port 27017 to MongoDB Connection String
-		// Which is <MONGODB DEFAULT PORT>
-		conStr += ":27017"
+// Reports if MONGODB_ENABLED is true | the connection might still fail
+func MongoEnabled() bool {
+	return mongoBackend.enabled
+}
+
+// Function Used to Connect the MongoDB Client | Called by MongoClient the first time MongoDB is used
+// which is why failures are returned instead of exiting the process
+func connectMongoDB(ctx context.Context) (*mongo.Client, error) {
+	settings := PhoeniciaDigitalConfig.Config().Mongo
 
+	// MONGODB_DATABASE is checked to be set when MONGODB_ENABLED is true while the config is loaded
+	conStr := "mongodb://"
+
+	// Check If MongoDB user field is no empty and add the username:password@ field to the connection string
+	// In case there is no username field filledout it will ignore this and continue to implement the
+	// Connection String disregarding the user and password
+	if settings.Mongo_user != "" {
+		conStr += fmt.Sprintf("%s:%s@", settings.Mongo_user, settings.Mongo_password)
 	}
 
+	// The host defaults to {Project Name}-Mongodb & the port to 27017 <MONGODB DEFAULT PORT> due to how our
+	// Dockerfile & docker-compose are set up | Both are validated when the config is loaded
+	conStr += fmt.Sprintf("%s:%d", settings.Mongo_host, settings.Mongo_port)
+
 	// Check the ssl type for The MongoDB Client Connection
-	if PhoeniciaDigitalConfig.Config.Mongo.Mongo_ssl == "true" {
+	if settings.Mongo_ssl {
 		// if the sll is set to true
 		conStr += "/?ssl=true"
 	}
 
-	// Try and connect to the MongoDB Client with the generated Connection String With all fields specified
-	if clientConnection, err := mongo.Connect(context.Background(), options.Client().ApplyURI(conStr)); err != nil {
-		// If there was an error connecting Exist the process Logging the Error
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to create MongoDB client | Verify MONGODB_HOST: %s | Verify MONGODB_PORT: %s", PhoeniciaDigitalConfig.Config.Mongo.Mongo_host, PhoeniciaDigitalConfig.Config.Mongo.Mongo_port))
-		log.Fatalf("Failed to create MongoDB client | Verify MONGODB_HOST: %s | Verify MONGODB_PORT: %s", PhoeniciaDigitalConfig.Config.Mongo.Mongo_host, PhoeniciaDigitalConfig.Config.Mongo.Mongo_port)
-		return nil
-	} else {
-		// In case Connection returned a *mongo.Client & No Errors occured set mongoDB.Client to struct mongodb
-		// This Struct is specific to Phoenicia Digital Check Line 15 To Line 17
-		mongoDB.Client = clientConnection
+	// Create the MongoDB Client with the generated Connection String With all fields specified
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(conStr).SetPoolMonitor(mongoPoolMonitor))
+	if err != nil {
+		return nil, err
 	}
 
-	// Try and Ping the MongoDB Client Making Sure that a positive connection has been established
-	if err := mongoDB.Client.Ping(context.Background(), nil); err != nil {
-		// In case there was an error returned EXIST the process Logging the Error
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to connect MongoDB client: %s | Service might be down or WRONG PORT | ERROR: %s", conStr, err.Error()))
-		log.Fatalf("Failed to connect MongoDB client: %s | Service might be down or WRONG PORT | ERROR: %s", conStr, err.Error())
-		return nil
-	} else {
-		// If the Ping was successful Return a database handle and set it to mongoDB.DB
-		mongoDB.DB = mongoDB.Client.Database(PhoeniciaDigitalConfig.Config.Mongo.Mongo_db)
+	// Creating the Client does not connect | Ping it Making Sure that a positive connection has been established
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
 	}
 
-	log.Printf("Implemented Mongodb Database connection | settings: %s\n", conStr)
-	// In case all fields are populated as needed & Pings were successful return the Client Struct
-	return mongoDB
+	PhoeniciaDigitalUtils.Logger.Info("Implemented Mongodb Database connection", "host", settings.Mongo_host, "port", settings.Mongo_port, "database", settings.Mongo_db)
+	return client, nil
 }
@@ -0,0 +1,160 @@
+// File: `Database Monitoring File` base/database/monitor.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// With docker-compose `depends_on` the API often starts before the databases accept connections | Connect waits for
+// them retrying with exponential backoff & jitter while Monitor pings them once running so /readyz reports the ones
+// that went down & they are reconnected once they are back
+
+// DB_CONNECT_* | how long to wait between the attempts & for how long to keep trying
+type retryPolicy struct {
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxWait        time.Duration
+}
+
+// The wait before the next attempt | a random point of the upper half of the backoff so instances restarted together
+// do not retry in lockstep
+func (r retryPolicy) jitter(backoff time.Duration) time.Duration {
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// The state of a database as reported by /readyz & the metrics
+type Status struct {
+	Backend   string // postgres | mongo | redis
+	Enabled   bool
+	Available bool
+	Error     error // why it is not available
+}
+
+// Connect every enabled database retrying until DB_CONNECT_MAX_WAIT passed | the databases are waited for concurrently
+// Returns every database that could not be connected | used by serve to fail fast once the wait is over
+func Connect(ctx context.Context) error {
+	database := PhoeniciaDigitalConfig.Config().Database
+	settings := retryPolicy{initialBackoff: database.Db_connect_initial_backoff, maxBackoff: database.Db_connect_max_backoff, maxWait: database.Db_connect_max_wait}
+
+	errs := make([]error, len(backends))
+	var wg sync.WaitGroup
+	for i, backend := range backends {
+		wg.Add(1)
+		go func(i int, backend connection) {
+			defer wg.Done()
+			errs[i] = backend.waitConnected(ctx, settings)
+		}(i, backend)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (b *backend[T]) waitConnected(ctx context.Context, retry retryPolicy) error {
+	if !b.enabled {
+		return nil
+	}
+
+	started := time.Now()
+	backoff := retry.initialBackoff
+	for attempt := 1; ; attempt++ {
+		var err error
+		b.mu.Lock()
+		if !b.connected {
+			err = b.connectLocked()
+		}
+		b.mu.Unlock()
+
+		if err == nil {
+			if attempt > 1 {
+				PhoeniciaDigitalUtils.Logger.Info("Database is up", "backend", b.name, "attempts", attempt, "waited", time.Since(started).Round(time.Millisecond))
+			}
+			return nil
+		}
+
+		wait := retry.jitter(backoff)
+		if time.Since(started)+wait > retry.maxWait {
+			return fmt.Errorf("%s still down after %d attempts in %s | raise DB_CONNECT_MAX_WAIT if it needs longer to start: %w", b.name, attempt, time.Since(started).Round(time.Millisecond), err)
+		}
+
+		PhoeniciaDigitalUtils.Logger.Warn("Waiting for database", "backend", b.name, "attempt", attempt, "retry_in", wait.Round(time.Millisecond), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, retry.maxBackoff)
+	}
+}
+
+// Ping the database (connecting it first if it is not yet) & log when it goes down or comes back
+func (b *backend[T]) check(ctx context.Context) {
+	if !b.enabled {
+		return
+	}
+
+	client, err := b.get(ctx)
+	if err == nil {
+		pingCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		err = b.ping(pingCtx, client)
+		cancel()
+	}
+
+	b.mu.Lock()
+	wasAvailable := b.available
+	b.available, b.lastErr = err == nil, err
+	b.mu.Unlock()
+
+	switch {
+	case wasAvailable && err != nil:
+		PhoeniciaDigitalUtils.Logger.Error("Database went down | /readyz reports it until it is back", "backend", b.name, "error", err)
+	case !wasAvailable && err == nil:
+		PhoeniciaDigitalUtils.Logger.Info("Database is available", "backend", b.name)
+	}
+}
+
+func (b *backend[T]) status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{Backend: b.id, Enabled: b.enabled, Available: b.enabled && b.available, Error: b.lastErr}
+	if status.Enabled && !status.Available && status.Error == nil {
+		status.Error = fmt.Errorf("%s is not connected yet", b.name)
+	}
+	return status
+}
+
+// Check every enabled database once every DB_MONITOR_INTERVAL until ctx is done | started by serve
+func Monitor(ctx context.Context) {
+	ticker := time.NewTicker(PhoeniciaDigitalConfig.Config().Database.Db_monitor_interval)
+	defer ticker.Stop()
+
+	for {
+		for _, backend := range backends {
+			backend.check(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// The state of every database as of the last check of Monitor (or the last connection attempt)
+func Statuses() []Status {
+	statuses := make([]Status, 0, len(backends))
+	for _, backend := range backends {
+		statuses = append(statuses, backend.status())
+	}
+	return statuses
+}
@@ -0,0 +1,230 @@
+// File: `Named Parameters File` base/database/params.go
+package PhoeniciaDigitalDatabase
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Query files can use :name placeholders instead of $1, $2, ... | they are replaced by positional ones once when the
+// file is prepared & bound from a map[string]any or a struct with `db` tags every time the query runs
+// ex: INSERT INTO readings (device, distance) VALUES (:device, :distance)
+//     Postgres.Exec(ctx, "insert_reading", map[string]any{"device": "pi", "distance": 12.5})
+// A name used twice is bound once | ::casts, strings, quoted identifiers & comments are left untouched
+// Inside array subscripts a colon is a slice ex: readings[1:n] | wrap a placeholder used there in parentheses
+// ex: degrees[(:index)] while ARRAY[:a, :b] constructors take placeholders as is
+
+func isParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isParamChar(c byte) bool {
+	return isParamStart(c) || (c >= '0' && c <= '9')
+}
+
+// Reports if the [ at offset i opens an ARRAY[...] constructor rather than a subscript
+func isArrayConstructor(query string, i int) bool {
+	before := strings.TrimRight(query[:i], " \t\r\n")
+	if len(before) < len("ARRAY") || !strings.EqualFold(before[len(before)-len("ARRAY"):], "ARRAY") {
+		return false
+	}
+	start := len(before) - len("ARRAY")
+	return start == 0 || !isParamChar(before[start-1])
+}
+
+// Replace the :name placeholders of a query by $1, $2, ... in order of first use | returns the names in that order
+// A query without any is returned as is so files using $1, $2, ... keep working
+func parseNamedParams(query string) (string, []string, error) {
+	var parsed strings.Builder
+	names, positions := []string{}, map[string]int{}
+	positional := false
+
+	// The open brackets & parentheses | '[' for subscripts, 'A' for ARRAY[ constructors & '(' for parentheses
+	nesting := []byte{}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		// 'strings' & "identifiers" | a doubled quote is an escaped one which the loop handles as two quoted parts
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated %c quote at offset %d", c, i)
+			}
+			parsed.WriteString(query[i : i+end+2])
+			i += end + 2
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			parsed.WriteString(query[i : i+end])
+			i += end
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			parsed.WriteString(query[i : i+end+4])
+			i += end + 4
+
+		// $1 positional placeholders or $tag$ dollar quoted strings
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				positional = true
+				parsed.WriteString(query[i:j])
+				i = j
+				break
+			}
+
+			for j < len(query) && isParamChar(query[j]) {
+				j++
+			}
+			if j < len(query) && query[j] == '$' {
+				tag := query[i : j+1]
+				end := strings.Index(query[j+1:], tag)
+				if end < 0 {
+					return "", nil, fmt.Errorf("unterminated %s quote at offset %d", tag, i)
+				}
+				parsed.WriteString(query[i : j+1+end+len(tag)])
+				i = j + 1 + end + len(tag)
+				break
+			}
+			parsed.WriteByte(c)
+			i++
+
+		// ::casts are written as is | only a single colon followed by a name is a placeholder
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			parsed.WriteString("::")
+			i += 2
+
+		// arr[1:n] & arr[:n] are slices of a subscript
+		case c == ':' && len(nesting) > 0 && nesting[len(nesting)-1] == '[':
+			parsed.WriteByte(c)
+			i++
+
+		case c == ':' && i+1 < len(query) && isParamStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isParamChar(query[j]) {
+				j++
+			}
+			name := query[i+1 : j]
+			position, ok := positions[name]
+			if !ok {
+				names = append(names, name)
+				position = len(names)
+				positions[name] = position
+			}
+			fmt.Fprintf(&parsed, "$%d", position)
+			i = j
+
+		default:
+			switch c {
+			case '[':
+				if isArrayConstructor(query, i) {
+					nesting = append(nesting, 'A')
+				} else {
+					nesting = append(nesting, '[')
+				}
+			case '(':
+				nesting = append(nesting, '(')
+			case ']', ')':
+				if len(nesting) > 0 {
+					nesting = nesting[:len(nesting)-1]
+				}
+			}
+			parsed.WriteByte(c)
+			i++
+		}
+	}
+
+	if len(names) == 0 {
+		return query, nil, nil
+	}
+	if positional {
+		return "", nil, fmt.Errorf("mixes :name & $1 placeholders | use one style per file")
+	}
+	return parsed.String(), names, nil
+}
+
+// The positional args of a query using :name placeholders | args must be a single map[string]any or struct (or a
+// pointer to one) whose `db` tags name the parameters
+// Every parameter must be given & every given value must be used so typos do not silently bind NULL or get dropped
+func bindNamedParams(names []string, args []any) ([]any, error) {
+	if len(names) == 0 {
+		return args, nil
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("uses :name placeholders so it takes a single map[string]any or struct with `db` tags got %d args", len(args))
+	}
+
+	values, err := namedValues(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	bound, missing := make([]any, len(names)), []string{}
+	for i, name := range names {
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		bound[i] = value
+		delete(values, name)
+	}
+
+	unused := make([]string, 0, len(values))
+	for name := range values {
+		unused = append(unused, name)
+	}
+	sort.Strings(unused)
+
+	switch {
+	case len(missing) > 0 && len(unused) > 0:
+		return nil, fmt.Errorf("missing parameters: %s & unused parameters: %s", strings.Join(missing, ", "), strings.Join(unused, ", "))
+	case len(missing) > 0:
+		return nil, fmt.Errorf("missing parameters: %s", strings.Join(missing, ", "))
+	case len(unused) > 0:
+		return nil, fmt.Errorf("unused parameters: %s", strings.Join(unused, ", "))
+	}
+	return bound, nil
+}
+
+// The values of a map[string]any or of the fields of a struct tagged `db:"name"` (`db:"-"` & untagged fields are skipped)
+func namedValues(arg any) (map[string]any, error) {
+	if values, ok := arg.(map[string]any); ok {
+		copied := make(map[string]any, len(values))
+		for name, value := range values {
+			copied[name] = value
+		}
+		return copied, nil
+	}
+
+	value := reflect.ValueOf(arg)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("named parameters must be a map[string]any or a struct with `db` tags got: %T", arg)
+	}
+
+	values := map[string]any{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		values[name] = value.Field(i).Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,270 @@
+// File: `Named Parameters Tests File` base/database/params_test.go
+package PhoeniciaDigitalDatabase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNamedParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      string
+		wantNames []string
+		wantErr   string
+	}{
+		{
+			name:  "query without placeholders is returned as is",
+			query: "SELECT * FROM readings",
+			want:  "SELECT * FROM readings",
+		},
+		{
+			name:  "positional placeholders are returned as is",
+			query: "SELECT * FROM readings WHERE device = $1 AND taken_at > $2",
+			want:  "SELECT * FROM readings WHERE device = $1 AND taken_at > $2",
+		},
+		{
+			name:      "placeholders are numbered in order of first use",
+			query:     "INSERT INTO readings (device, distance) VALUES (:device, :distance)",
+			want:      "INSERT INTO readings (device, distance) VALUES ($1, $2)",
+			wantNames: []string{"device", "distance"},
+		},
+		{
+			name:      "a repeated name is bound once",
+			query:     "SELECT * FROM readings WHERE device = :device OR :device IS NULL AND distance < :max",
+			want:      "SELECT * FROM readings WHERE device = $1 OR $1 IS NULL AND distance < $2",
+			wantNames: []string{"device", "max"},
+		},
+		{
+			name:      "names hold digits & underscores",
+			query:     "SELECT :from_1, :_to",
+			want:      "SELECT $1, $2",
+			wantNames: []string{"from_1", "_to"},
+		},
+		{
+			name:      "casts are left untouched",
+			query:     "SELECT :taken_at::timestamptz, distance::text FROM readings",
+			want:      "SELECT $1::timestamptz, distance::text FROM readings",
+			wantNames: []string{"taken_at"},
+		},
+		{
+			name:  "a colon not followed by a name is left untouched",
+			query: "SELECT ':' || name, 1 : 2",
+			want:  "SELECT ':' || name, 1 : 2",
+		},
+		{
+			name:      "single quoted strings are left untouched",
+			query:     "SELECT 'at :noon' WHERE device = :device",
+			want:      "SELECT 'at :noon' WHERE device = $1",
+			wantNames: []string{"device"},
+		},
+		{
+			name:      "escaped quotes keep the string open",
+			query:     "SELECT 'it''s :late' WHERE device = :device",
+			want:      "SELECT 'it''s :late' WHERE device = $1",
+			wantNames: []string{"device"},
+		},
+		{
+			name:      "quoted identifiers are left untouched",
+			query:     `SELECT "odd:name" FROM readings WHERE device = :device`,
+			want:      `SELECT "odd:name" FROM readings WHERE device = $1`,
+			wantNames: []string{"device"},
+		},
+		{
+			name:      "dollar quoted strings are left untouched",
+			query:     "SELECT $$ :inside $$, $body$ it's :inside $body$, :outside",
+			want:      "SELECT $$ :inside $$, $body$ it's :inside $body$, $1",
+			wantNames: []string{"outside"},
+		},
+		{
+			name:      "line comments are left untouched",
+			query:     "SELECT :a -- not :b\nFROM readings",
+			want:      "SELECT $1 -- not :b\nFROM readings",
+			wantNames: []string{"a"},
+		},
+		{
+			name:      "block comments are left untouched",
+			query:     "SELECT /* :hidden 'quote */ :shown",
+			want:      "SELECT /* :hidden 'quote */ $1",
+			wantNames: []string{"shown"},
+		},
+		{
+			name:      "array slices are left untouched",
+			query:     "SELECT degrees[1:n], degrees[i:n], degrees[:n], degrees[n:] FROM scans WHERE id = :id",
+			want:      "SELECT degrees[1:n], degrees[i:n], degrees[:n], degrees[n:] FROM scans WHERE id = $1",
+			wantNames: []string{"id"},
+		},
+		{
+			name:      "placeholders inside parentheses of a subscript are bound",
+			query:     "SELECT degrees[(:index)], degrees[1:(:last)] FROM scans",
+			want:      "SELECT degrees[($1)], degrees[1:($2)] FROM scans",
+			wantNames: []string{"index", "last"},
+		},
+		{
+			name:      "array constructors take placeholders",
+			query:     "SELECT ARRAY[:a, :b], array [:c] FROM scans",
+			want:      "SELECT ARRAY[$1, $2], array [$3] FROM scans",
+			wantNames: []string{"a", "b", "c"},
+		},
+		{
+			name:      "a column named like array is a subscript",
+			query:     "SELECT myarray[1:n] FROM scans WHERE id = :id",
+			want:      "SELECT myarray[1:n] FROM scans WHERE id = $1",
+			wantNames: []string{"id"},
+		},
+		{
+			name:    "mixing placeholder styles is an error",
+			query:   "SELECT * FROM readings WHERE device = :device AND distance < $1",
+			wantErr: "mixes :name & $1 placeholders",
+		},
+		{
+			name:    "unterminated string",
+			query:   "SELECT 'open :a",
+			wantErr: "unterminated ' quote",
+		},
+		{
+			name:    "unterminated identifier",
+			query:   `SELECT "open :a`,
+			wantErr: `unterminated " quote`,
+		},
+		{
+			name:    "unterminated dollar quote",
+			query:   "SELECT $tag$ open :a",
+			wantErr: "unterminated $tag$ quote",
+		},
+		{
+			name:    "unterminated block comment",
+			query:   "SELECT :a /* open",
+			wantErr: "unterminated comment",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, names, err := parseNamedParams(test.query)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("query = %q want %q", got, test.want)
+			}
+			if !reflect.DeepEqual(names, test.wantNames) {
+				t.Errorf("names = %q want %q", names, test.wantNames)
+			}
+		})
+	}
+}
+
+func TestBindNamedParams(t *testing.T) {
+	type reading struct {
+		Device   string  `db:"device"`
+		Distance float64 `db:"distance,omitempty"`
+		Note     string  `db:"-"`
+		Untagged int
+		internal string `db:"internal"`
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		args    []any
+		want    []any
+		wantErr string
+	}{
+		{
+			name: "positional args are returned as is",
+			args: []any{"pi", 12.5},
+			want: []any{"pi", 12.5},
+		},
+		{
+			name:  "map values are bound in placeholder order",
+			names: []string{"distance", "device"},
+			args:  []any{map[string]any{"device": "pi", "distance": 12.5}},
+			want:  []any{12.5, "pi"},
+		},
+		{
+			name:  "nil values are bound as NULL",
+			names: []string{"device"},
+			args:  []any{map[string]any{"device": nil}},
+			want:  []any{nil},
+		},
+		{
+			name:  "struct fields are bound by their db tags",
+			names: []string{"device", "distance"},
+			args:  []any{reading{Device: "pi", Distance: 12.5, Note: "skipped", Untagged: 1}},
+			want:  []any{"pi", 12.5},
+		},
+		{
+			name:  "pointers to structs are followed",
+			names: []string{"device", "distance"},
+			args:  []any{&reading{Device: "pi", internal: "skipped"}},
+			want:  []any{"pi", 0.0},
+		},
+		{
+			name:    "missing parameters",
+			names:   []string{"device", "distance", "degree"},
+			args:    []any{map[string]any{"device": "pi"}},
+			wantErr: "missing parameters: distance, degree",
+		},
+		{
+			name:    "unused parameters are sorted",
+			names:   []string{"device"},
+			args:    []any{map[string]any{"device": "pi", "status": "Success", "degree": 90}},
+			wantErr: "unused parameters: degree, status",
+		},
+		{
+			name:    "missing & unused parameters are reported together",
+			names:   []string{"device"},
+			args:    []any{map[string]any{"devise": "pi"}},
+			wantErr: "missing parameters: device & unused parameters: devise",
+		},
+		{
+			name:    "named parameters take a single arg",
+			names:   []string{"device"},
+			args:    []any{"pi", 12.5},
+			wantErr: "takes a single map[string]any or struct",
+		},
+		{
+			name:    "named parameters need a map or struct",
+			names:   []string{"device"},
+			args:    []any{"pi"},
+			wantErr: "must be a map[string]any or a struct",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := bindNamedParams(test.names, test.args)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("args = %v want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBindNamedParamsDoesNotChangeTheMap(t *testing.T) {
+	values := map[string]any{"device": "pi", "distance": 12.5}
+	if _, err := bindNamedParams([]string{"device", "distance"}, []any{values}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 {
+		t.Errorf("the map given was changed: %v", values)
+	}
+}
@@ -0,0 +1,211 @@
+// File: `Postgres Queries File` base/database/query.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Context aware helpers running the query files of the sql folder through their prepared statements
+// Every query gets POSTGRES_QUERY_TIMEOUT (or its entry of POSTGRES_QUERY_TIMEOUTS) on top of the ctx it is given | for
+// QueryRows it only covers running the query so reading a long result (ex: an /export stream) is never cut off
+// ex: rows, err := PhoeniciaDigitalDatabase.Postgres.QueryRows(r.Context(), "export_readings", from, to, device)
+
+// The *sql.Rows of QueryRows | Close also releases the ctx of the query so DONT FORGET TO defer rows.Close()
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// The *sql.Row of QueryRow | Scan releases the timeout of the query once the row is read
+type Row struct {
+	row    *sql.Row
+	cancel context.CancelFunc
+}
+
+func (r *Row) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
+
+// The query files run inside a transaction of WithTx | same helpers as Postgres
+type Tx struct {
+	tx *sql.Tx
+}
+
+// The timeout of a single query | POSTGRES_QUERY_TIMEOUTS[fileName] then POSTGRES_QUERY_TIMEOUT (0 means no timeout)
+func queryTimeout(fileName string) time.Duration {
+	settings := PhoeniciaDigitalConfig.Config().Postgres
+	if override, ok := settings.Postgres_query_timeouts[fileName]; ok {
+		return override
+	}
+	return settings.Postgres_query_timeout
+}
+
+// The ctx of a query read at once (QueryRow & Exec) | the timeout covers the whole query
+func queryContext(ctx context.Context, fileName string) (context.Context, context.CancelFunc) {
+	if timeout := queryTimeout(fileName); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Run the query of stmt giving it timeout to return its rows | the timeout stops once the query returned so the rows
+// are read under ctx alone | cancel releases the ctx of the rows once they are closed
+func startQuery(ctx context.Context, timeout time.Duration, stmt *sql.Stmt, args []any) (*sql.Rows, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	release := func() { cancel(nil) }
+
+	expired := func() bool { return false }
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+		expired = func() bool { return !timer.Stop() }
+	}
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if expired() {
+		// The rows of a query that returned right as the timeout fired are closed by the cancel
+		if err == nil {
+			rows.Close()
+		}
+		err = fmt.Errorf("query did not return within %s: %w", timeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// The prepared statement of a query file & its bound args | bound to tx when the query runs inside a transaction
+func statement(ctx context.Context, tx *sql.Tx, fileName string, args []any) (*sql.Stmt, []any, error) {
+	prepared, err := Postgres.prepare(ctx, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Args are positional or a single map/struct for files using :name placeholders
+	args, err = prepared.bind(ctx, fileName, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tx == nil {
+		return prepared.stmt, args, nil
+	}
+	// Closed by database/sql once the transaction ends
+	return tx.StmtContext(ctx, prepared.stmt), args, nil
+}
+
+func queryRows(ctx context.Context, tx *sql.Tx, fileName string, args ...any) (*Rows, error) {
+	stmt, args, err := statement(ctx, tx, fileName, args)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cancel, err := startQuery(ctx, queryTimeout(fileName), stmt, args)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error Querying rows", "file", fileName, "error", err)
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+func queryRow(ctx context.Context, tx *sql.Tx, fileName string, args ...any) (*Row, error) {
+	ctx, cancel := queryContext(ctx, fileName)
+	stmt, args, err := statement(ctx, tx, fileName, args)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Row{row: stmt.QueryRowContext(ctx, args...), cancel: cancel}, nil
+}
+
+func exec(ctx context.Context, tx *sql.Tx, fileName string, args ...any) (sql.Result, error) {
+	ctx, cancel := queryContext(ctx, fileName)
+	defer cancel()
+
+	stmt, args, err := statement(ctx, tx, fileName, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error Executing Query", "file", fileName, "error", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Run a query file returning every row | DONT FORGET TO defer rows.Close()
+func (p postgres) QueryRows(ctx context.Context, fileName string, args ...any) (*Rows, error) {
+	return queryRows(ctx, nil, fileName, args...)
+}
+
+// Run a query file returning a single row | sql.ErrNoRows is returned by Scan when there is none
+func (p postgres) QueryRow(ctx context.Context, fileName string, args ...any) (*Row, error) {
+	return queryRow(ctx, nil, fileName, args...)
+}
+
+// Run a query file returning no rows ex: INSERT, UPDATE & DELETE
+func (p postgres) Exec(ctx context.Context, fileName string, args ...any) (sql.Result, error) {
+	return exec(ctx, nil, fileName, args...)
+}
+
+func (t *Tx) QueryRows(ctx context.Context, fileName string, args ...any) (*Rows, error) {
+	return queryRows(ctx, t.tx, fileName, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, fileName string, args ...any) (*Row, error) {
+	return queryRow(ctx, t.tx, fileName, args...)
+}
+
+func (t *Tx) Exec(ctx context.Context, fileName string, args ...any) (sql.Result, error) {
+	return exec(ctx, t.tx, fileName, args...)
+}
+
+// Run fn inside a transaction committed once fn returns nil | rolled back when fn returns an error or panics (the
+// panic carries on once the transaction is rolled back)
+// ex: err := Postgres.WithTx(ctx, func(tx *Tx) error { _, err := tx.Exec(ctx, "insert_reading", ...); return err })
+func (p postgres) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	db, err := postgresDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+			panic(recovered)
+		}
+	}()
+
+	if err := fn(&Tx{tx: tx}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rolling back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// File: `Postgres Queries Tests File` base/database/query_test.go
+package PhoeniciaDigitalDatabase
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// A database/sql driver whose queries take `delay` to return `count` rows | lets the timeouts run without Postgres
+type slowDriver struct{}
+
+type slowConn struct{}
+
+type slowStmt struct{}
+
+type slowRows struct {
+	left int
+}
+
+func (slowDriver) Open(string) (driver.Conn, error) { return slowConn{}, nil }
+
+func (slowConn) Prepare(string) (driver.Stmt, error) { return slowStmt{}, nil }
+func (slowConn) Close() error                        { return nil }
+func (slowConn) Begin() (driver.Tx, error)           { return nil, errors.New("transactions are not supported") }
+
+func (slowStmt) Close() error  { return nil }
+func (slowStmt) NumInput() int { return 2 }
+func (slowStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+func (slowStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("use QueryContext")
+}
+
+// The args are the delay in nanoseconds & the number of rows | ex: SELECT $1, $2
+func (slowStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	select {
+	case <-time.After(time.Duration(args[0].Value.(int64))):
+		return &slowRows{left: int(args[1].Value.(int64))}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *slowRows) Columns() []string { return []string{"n"} }
+func (r *slowRows) Close() error      { return nil }
+func (r *slowRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	dest[0] = int64(r.left)
+	r.left--
+	return nil
+}
+
+func init() {
+	sql.Register("pd-slow", slowDriver{})
+}
+
+func TestStartQuery(t *testing.T) {
+	db, err := sql.Open("pd-slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	stmt, err := db.Prepare("SELECT $1, $2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		delay     time.Duration // taken by the query to return
+		readAfter time.Duration // waited before reading the rows
+		wantRows  int
+		wantErr   error
+	}{
+		{name: "rows are read after the timeout passed", timeout: 20 * time.Millisecond, readAfter: 60 * time.Millisecond, wantRows: 3},
+		{name: "no timeout", readAfter: 10 * time.Millisecond, wantRows: 3},
+		{name: "query slower than the timeout", timeout: 20 * time.Millisecond, delay: time.Second, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, cancel, err := startQuery(context.Background(), test.timeout, stmt, []any{int64(test.delay), int64(3)})
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("error = %v want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer cancel()
+			defer rows.Close()
+
+			time.Sleep(test.readAfter)
+			read := 0
+			for rows.Next() {
+				read++
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("reading the rows failed: %v", err)
+			}
+			if read != test.wantRows {
+				t.Errorf("read %d rows want %d", read, test.wantRows)
+			}
+		})
+	}
+}
+
+func TestStartQueryFollowsTheCallerContext(t *testing.T) {
+	db, err := sql.Open("pd-slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	stmt, err := db.Prepare("SELECT $1, $2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelCaller := context.WithCancel(context.Background())
+	rows, cancel, err := startQuery(ctx, time.Second, stmt, []any{int64(0), int64(3)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cancel()
+	defer rows.Close()
+
+	// A client leaving mid-stream still stops the rows
+	cancelCaller()
+	time.Sleep(10 * time.Millisecond)
+	for rows.Next() {
+	}
+	if err := rows.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("rows error = %v want %v", err, context.Canceled)
+	}
+}
@@ -3,53 +3,61 @@ package PhoeniciaDigitalDatabase
 import (
 	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
 	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
 	"fmt"
-	"log"
-	"strconv"
 
 	"github.com/redis/go-redis/v9"
 )
 
-var Redis *redis.Client = implementRedisDB()
+var redisBackend = &backend[*redis.Client]{
+	id:         "redis",
+	name:       "Redis",
+	setting:    "REDIS_ENABLED",
+	enabled:    PhoeniciaDigitalConfig.Config().Redis.Redis_enabled,
+	connect:    connectRedis,
+	ping:       func(ctx context.Context, client *redis.Client) error { return client.Ping(ctx).Err() },
+	disconnect: (*redis.Client).Close,
+}
 
-func implementRedisDB() *redis.Client {
+// The Redis Client | connects on the first call
+// Returns a *DisabledError when REDIS_ENABLED is false
+func RedisClient() (*redis.Client, error) {
+	return redisBackend.get(context.Background())
+}
 
-	// Set a conStr Variable That will be used as the connection string to our Redis Database
-	var conStr string
+// Reports if REDIS_ENABLED is true | the connection might still fail
+func RedisEnabled() bool {
+	return redisBackend.enabled
+}
 
-	if PhoeniciaDigitalConfig.Config.Redis.Redis_host != "" {
-		conStr += PhoeniciaDigitalConfig.Config.Redis.Redis_host
-	} else {
-		conStr += "localhost"
+// The statistics of the connection pool | false until connected
+func RedisPoolStats() (*redis.PoolStats, bool) {
+	client, connected := redisBackend.current()
+	if !connected {
+		return nil, false
 	}
+	return client.PoolStats(), true
+}
 
-	if PhoeniciaDigitalConfig.Config.Redis.Redis_port != "" {
-		if portNum, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Redis.Redis_port); err != nil {
-			// In case the provided port is not an integer the API will consider it an error
-			// And will Exist the process logging the error & issue
-			PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to implement Redis client | Invalid Port Number: %s", PhoeniciaDigitalConfig.Config.Redis.Redis_port))
-			log.Fatalf("Failed to implement Redis client | Invalid Port Number: %s", PhoeniciaDigitalConfig.Config.Redis.Redis_port)
-			return nil
-		} else {
-			if portNum < 0 || portNum > 65535 {
-				// In case the provided port is not in range 0 <-> 65535 the API will consider it an error
-				// And will Exist the process logging the error & issue
-				PhoeniciaDigitalUtils.Log(fmt.Sprintf("Redis PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalConfig.Config.Redis.Redis_port))
-				log.Fatalf("Redis PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalConfig.Config.Redis.Redis_port)
-				return nil
-			} else {
-				// If all is good and checked append the specific Port to the MongoDB Connection string
-				conStr += fmt.Sprintf(":%s", PhoeniciaDigitalConfig.Config.Redis.Redis_port)
-			}
-		}
-	} else {
-		conStr += ":6379"
-	}
+func connectRedis(ctx context.Context) (*redis.Client, error) {
+	settings := PhoeniciaDigitalConfig.Config().Redis
 
-	return redis.NewClient(&redis.Options{
+	// The host defaults to localhost & the port to 6379 | validated when the config is loaded
+	conStr := fmt.Sprintf("%s:%d", settings.Redis_host, settings.Redis_port)
+
+	client := redis.NewClient(&redis.Options{
 		Addr:     conStr,
-		Password: PhoeniciaDigitalConfig.Config.Redis.Redis_password,
+		Password: settings.Redis_password,
 		DB:       0,
 	})
 
+	// Creating the Client does not connect | Ping it so a wrong address or password shows on first use
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Implemented Redis Database connection", "address", conStr)
+	return client, nil
+
 }
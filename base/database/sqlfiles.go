@@ -0,0 +1,47 @@
+// File: `SQL Files File` base/database/sqlfiles.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	PhoeniciaDigitalSQL "Phoenicia-Digital-Base-API/sql"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The query files & migrations | built into the binary by default so it runs from any directory
+// POSTGRES_SQL_DIR (checked to be a folder while the config is loaded) reads them from disk instead while developing
+var sqlFiles fs.FS = openSQLFiles()
+
+func openSQLFiles() fs.FS {
+	if dir := PhoeniciaDigitalConfig.Config().Postgres.Postgres_sql_dir; dir != "" {
+		return os.DirFS(dir)
+	}
+	return PhoeniciaDigitalSQL.Files
+}
+
+// Where the query files are read from | logged at startup & shown in errors
+func SQLSource() string {
+	if dir := PhoeniciaDigitalConfig.Config().Postgres.Postgres_sql_dir; dir != "" {
+		return dir
+	}
+	return "embedded"
+}
+
+// The name of every query file as ReadSQL & the query helpers take it ex: insert_reading | migrations are not listed
+func QueryNames() ([]string, error) {
+	entries, err := fs.ReadDir(sqlFiles, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if name, isSQL := strings.CutSuffix(entry.Name(), ".sql"); isSQL && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
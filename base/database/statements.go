@@ -0,0 +1,172 @@
+// File: `Prepared Statements File` base/database/statements.go
+package PhoeniciaDigitalDatabase
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// Every query file is read, parsed (:name placeholders) & prepared once then its *sql.Stmt is shared by every caller | a *sql.Stmt is safe
+// for concurrent use & database/sql prepares it again by itself on every connection of the pool running it
+// With POSTGRES_SQL_RELOAD the file is checked on every use & prepared again once it changed
+
+type preparedStatement struct {
+	stmt     *sql.Stmt
+	params   []string  // the :name placeholders in the order of their $1, $2, ... | empty for positional files
+	modified time.Time // of the .sql file when it was read | only kept with POSTGRES_SQL_RELOAD
+}
+
+type statementRegistry struct {
+	mu         sync.RWMutex
+	db         *sql.DB // the pool the statements were prepared on
+	statements map[string]*preparedStatement
+}
+
+var statements = &statementRegistry{statements: map[string]*preparedStatement{}}
+
+// Query files that PrepareAll skips since they are not queries run through the registry
+var unprepared = map[string]bool{
+	"init":                     true, // every table at once | run by the postgres container
+	"create_schema_migrations": true, // the migration queries run on the connection holding the migration lock (or
+	"select_schema_migrations": true, // directly for the status) & schema_migrations might not exist before the first one
+	"insert_schema_migration":  true,
+	"delete_schema_migration":  true,
+}
+
+// The prepared statement of a query file | prepares it the first time & again once the file changed (POSTGRES_SQL_RELOAD)
+// ctx carries the request ID into the logs | a statement prepared for one request is shared by every other one
+func (r *statementRegistry) get(ctx context.Context, db *sql.DB, fileName string) (*preparedStatement, error) {
+	reload := PhoeniciaDigitalConfig.Config().Postgres.Postgres_sql_reload
+
+	var modified time.Time
+	if reload {
+		stat, err := fs.Stat(sqlFiles, fileName+".sql")
+		if err != nil {
+			PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error reading query file", "file", fileName, "source", SQLSource(), "error", err)
+			return nil, err
+		}
+		modified = stat.ModTime()
+	}
+
+	r.mu.RLock()
+	prepared, ok := r.statements[fileName]
+	current := ok && r.db == db && prepared.modified.Equal(modified)
+	r.mu.RUnlock()
+	if current {
+		return prepared, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A new pool means the connection was closed & opened again | the old statements belong to the closed one
+	if r.db != db {
+		r.closeLocked()
+		r.db = db
+	}
+
+	// Another caller might have prepared it while this one waited for the lock
+	prepared, ok = r.statements[fileName]
+	if ok && prepared.modified.Equal(modified) {
+		return prepared, nil
+	}
+
+	query, err := readSQL(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The :name placeholders are parsed once here | see ./params.go
+	query, params, err := parseNamedParams(query)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error parsing query parameters", "file", fileName, "error", err)
+		return nil, err
+	}
+
+	// PREPARING QUERIES IS THE SAFEST METHOD TO USE QUERIES SINCE THEY PREVENT SQL INJECTIONS
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error preparing query", "file", fileName, "query", query, "error", err)
+		return nil, err
+	}
+
+	// Only reached with POSTGRES_SQL_RELOAD | a caller still holding the old statement gets `sql: statement is closed`
+	// which is fine while developing
+	if ok {
+		prepared.stmt.Close()
+		PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Query file changed | Prepared it again", "file", fileName)
+	}
+
+	prepared = &preparedStatement{stmt: stmt, params: params, modified: modified}
+	r.statements[fileName] = prepared
+	return prepared, nil
+}
+
+// The args of a query run with the statement | a map or struct for files using :name placeholders
+func (p *preparedStatement) bind(ctx context.Context, fileName string, args []any) ([]any, error) {
+	bound, err := bindNamedParams(p.params, args)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(ctx, "Error binding query parameters", "file", fileName, "error", err)
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return bound, nil
+}
+
+// Close every statement | the caller must hold r.mu
+func (r *statementRegistry) closeLocked() {
+	for fileName, prepared := range r.statements {
+		prepared.stmt.Close()
+		delete(r.statements, fileName)
+	}
+}
+
+// Close every statement before the connection closes | the next use prepares them again on the new connection
+func (r *statementRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closeLocked()
+	r.db = nil
+}
+
+// Prepare every query file | serve calls it with POSTGRES_PREPARE_ON_START so a syntax error or a missing
+// table stops the start instead of failing the first request using the query
+// Every invalid file is reported together
+func (p postgres) PrepareAll() error {
+	db, err := PostgresDB()
+	if err != nil {
+		return err
+	}
+
+	queries, err := QueryNames()
+	if err != nil {
+		return err
+	}
+
+	names, errs := []string{}, []error{}
+	for _, fileName := range queries {
+		if unprepared[fileName] {
+			continue
+		}
+
+		if _, err := statements.get(context.Background(), db, fileName); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fileName, err))
+			continue
+		}
+		names = append(names, fileName)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Prepared every query file", "queries", names)
+	return nil
+}
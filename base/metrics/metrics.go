@@ -0,0 +1,287 @@
+// File: `Metrics Implementation File` base/metrics/metrics.go
+package PhoeniciaDigitalMetrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default histogram buckets in seconds | Same as the Prometheus client defaults
+var DefaultBuckets []float64 = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// A metric that knows how to write itself in the Prometheus text exposition format
+type metric interface {
+	write(w io.Writer)
+}
+
+// The registry every metric created through NewCounter, NewGauge & NewHistogram is added to
+// collectors are called right before every scrape so values read from elsewhere (e.g. database pools) are fresh
+var registry = struct {
+	sync.Mutex
+	metrics    []metric
+	collectors []func()
+}{}
+
+func register(m metric) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.metrics = append(registry.metrics, m)
+}
+
+// Register a function that is called before every scrape to update gauges from an outside source
+func RegisterCollector(collector func()) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.collectors = append(registry.collectors, collector)
+}
+
+// Write every registered metric in the Prometheus text exposition format
+func WriteText(w io.Writer) {
+	registry.Lock()
+	collectors := append([]func(){}, registry.collectors...)
+	metrics := append([]metric{}, registry.metrics...)
+	registry.Unlock()
+
+	for _, collector := range collectors {
+		collector()
+	}
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// The http.Handler serving the metrics to a Prometheus scraper
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+// The shared part of every metric | a name, a help text & the label names every series is keyed by
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (f family) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+// Join the label values into the map key of a series | panics on a label count mismatch since that is a programming error
+func (f family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values got %d", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// Format the labels of a series as {name="value",...} with optional extra label appended (used for le)
+func (f family) labelText(key string, extraName string, extraValue string) string {
+	pairs := []string{}
+	if len(f.labels) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, f.labels[i], escapeLabel(value)))
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, escapeLabel(extraValue)))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Help texts escape backslashes & line feeds | label values also escape double quotes
+func escapeHelp(help string) string {
+	help = strings.ReplaceAll(help, `\`, `\\`)
+	return strings.ReplaceAll(help, "\n", `\n`)
+}
+
+func escapeLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func sortedKeys[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is a value that only goes up (requests served, degrees travelled, ...)
+type Counter struct {
+	family
+	mu     sync.Mutex
+	series map[string]float64
+}
+
+func NewCounter(name string, help string, labels ...string) *Counter {
+	c := &Counter{family: family{name: name, help: help, kind: "counter", labels: labels}, series: map[string]float64{}}
+	// A metric without labels has exactly one series which is exposed as 0 until it is first updated
+	if len(labels) == 0 {
+		c.series[""] = 0
+	}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add a value to the counter | negative values are ignored since a counter can never decrease
+func (c *Counter) Add(value float64, labels ...string) {
+	if value < 0 {
+		return
+	}
+	key := c.key(labels)
+	c.mu.Lock()
+	c.series[key] += value
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w)
+	for _, key := range sortedKeys(c.series) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelText(key, "", ""), formatValue(c.series[key]))
+	}
+}
+
+// Gauge is a value that can go up & down (connected clients, loiter state, pool sizes, ...)
+type Gauge struct {
+	family
+	mu     sync.Mutex
+	series map[string]float64
+}
+
+func NewGauge(name string, help string, labels ...string) *Gauge {
+	g := &Gauge{family: family{name: name, help: help, kind: "gauge", labels: labels}, series: map[string]float64{}}
+	// A metric without labels has exactly one series which is exposed as 0 until it is first updated
+	if len(labels) == 0 {
+		g.series[""] = 0
+	}
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(value float64, labels ...string) {
+	key := g.key(labels)
+	g.mu.Lock()
+	g.series[key] = value
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Add(value float64, labels ...string) {
+	key := g.key(labels)
+	g.mu.Lock()
+	g.series[key] += value
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Inc(labels ...string) {
+	g.Add(1, labels...)
+}
+
+func (g *Gauge) Dec(labels ...string) {
+	g.Add(-1, labels...)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.header(w)
+	for _, key := range sortedKeys(g.series) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.labelText(key, "", ""), formatValue(g.series[key]))
+	}
+}
+
+// Histogram counts observations (durations, sizes, ...) into cumulative buckets
+type Histogram struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	h := &Histogram{family: family{name: name, help: help, kind: "histogram", labels: labels}, buckets: sorted, series: map[string]*histogramSeries{}}
+	if len(labels) == 0 {
+		h.series[""] = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+	}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(value float64, labels ...string) {
+	key := h.key(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelText(key, "le", formatValue(bound)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelText(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelText(key, "", ""), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelText(key, "", ""), s.count)
+	}
+}
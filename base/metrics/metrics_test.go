@@ -0,0 +1,254 @@
+// File: `Metrics Tests File` base/metrics/metrics_test.go
+package PhoeniciaDigitalMetrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// The text a single metric writes | compared line by line against the Prometheus text exposition format
+func written(m metric) string {
+	var text strings.Builder
+	m.write(&text)
+	return text.String()
+}
+
+func TestCounterText(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		update func(c *Counter)
+		want   string
+	}{
+		{
+			name:   "unlabelled counter is exposed as 0 before any update",
+			update: func(c *Counter) {},
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n" +
+				"test_counter_total 0\n",
+		},
+		{
+			name: "unlabelled counter adds up",
+			update: func(c *Counter) {
+				c.Inc()
+				c.Add(2.5)
+			},
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n" +
+				"test_counter_total 3.5\n",
+		},
+		{
+			name:   "negative values are ignored",
+			update: func(c *Counter) { c.Inc(); c.Add(-5) },
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n" +
+				"test_counter_total 1\n",
+		},
+		{
+			name:   "labelled counter has no series before any update",
+			labels: []string{"route", "code"},
+			update: func(c *Counter) {},
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n",
+		},
+		{
+			name:   "labelled series are sorted by their label values",
+			labels: []string{"route", "code"},
+			update: func(c *Counter) {
+				c.Inc("GET /rotate-right", "200")
+				c.Inc("GET /loiter", "429")
+				c.Inc("GET /loiter", "200")
+				c.Inc("GET /loiter", "200")
+			},
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n" +
+				`test_counter_total{route="GET /loiter",code="200"} 2` + "\n" +
+				`test_counter_total{route="GET /loiter",code="429"} 1` + "\n" +
+				`test_counter_total{route="GET /rotate-right",code="200"} 1` + "\n",
+		},
+		{
+			name:   "label values escape backslashes, double quotes & line feeds",
+			labels: []string{"path"},
+			update: func(c *Counter) { c.Inc("C:\\logs\n\"quoted\"") },
+			want: "# HELP test_counter_total Test counter.\n" +
+				"# TYPE test_counter_total counter\n" +
+				`test_counter_total{path="C:\\logs\n\"quoted\""} 1` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewCounter("test_counter_total", "Test counter.", test.labels...)
+			test.update(c)
+			if got := written(c); got != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGaugeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		update func(g *Gauge)
+		want   string
+	}{
+		{
+			name:   "unlabelled gauge is exposed as 0 before any update",
+			update: func(g *Gauge) {},
+			want: "# HELP test_gauge Test gauge.\n" +
+				"# TYPE test_gauge gauge\n" +
+				"test_gauge 0\n",
+		},
+		{
+			name: "gauge goes up & down",
+			update: func(g *Gauge) {
+				g.Inc()
+				g.Inc()
+				g.Dec()
+				g.Add(-3)
+			},
+			want: "# HELP test_gauge Test gauge.\n" +
+				"# TYPE test_gauge gauge\n" +
+				"test_gauge -2\n",
+		},
+		{
+			name:   "set replaces the value of a series only",
+			labels: []string{"backend"},
+			update: func(g *Gauge) {
+				g.Set(4, "postgres")
+				g.Set(1, "redis")
+				g.Set(7, "postgres")
+			},
+			want: "# HELP test_gauge Test gauge.\n" +
+				"# TYPE test_gauge gauge\n" +
+				`test_gauge{backend="postgres"} 7` + "\n" +
+				`test_gauge{backend="redis"} 1` + "\n",
+		},
+		{
+			name:   "special values",
+			labels: []string{"value"},
+			update: func(g *Gauge) {
+				g.Set(math.Inf(1), "a")
+				g.Set(math.Inf(-1), "b")
+				g.Set(math.NaN(), "c")
+				g.Set(1e-06, "d")
+				g.Set(12345678, "e")
+			},
+			want: "# HELP test_gauge Test gauge.\n" +
+				"# TYPE test_gauge gauge\n" +
+				`test_gauge{value="a"} +Inf` + "\n" +
+				`test_gauge{value="b"} -Inf` + "\n" +
+				`test_gauge{value="c"} NaN` + "\n" +
+				`test_gauge{value="d"} 1e-06` + "\n" +
+				`test_gauge{value="e"} 1.2345678e+07` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGauge("test_gauge", "Test gauge.", test.labels...)
+			test.update(g)
+			if got := written(g); got != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHistogramText(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets []float64
+		labels  []string
+		observe func(h *Histogram)
+		want    string
+	}{
+		{
+			name:    "unlabelled histogram exposes empty buckets before any observation",
+			buckets: []float64{0.1, 1},
+			observe: func(h *Histogram) {},
+			want: "# HELP test_seconds Test histogram.\n" +
+				"# TYPE test_seconds histogram\n" +
+				`test_seconds_bucket{le="0.1"} 0` + "\n" +
+				`test_seconds_bucket{le="1"} 0` + "\n" +
+				`test_seconds_bucket{le="+Inf"} 0` + "\n" +
+				"test_seconds_sum 0\n" +
+				"test_seconds_count 0\n",
+		},
+		{
+			name:    "buckets are cumulative & sorted with the bound itself included",
+			buckets: []float64{1, 0.1},
+			observe: func(h *Histogram) {
+				h.Observe(0.05)
+				h.Observe(0.1)
+				h.Observe(0.5)
+				h.Observe(3)
+			},
+			want: "# HELP test_seconds Test histogram.\n" +
+				"# TYPE test_seconds histogram\n" +
+				`test_seconds_bucket{le="0.1"} 2` + "\n" +
+				`test_seconds_bucket{le="1"} 3` + "\n" +
+				`test_seconds_bucket{le="+Inf"} 4` + "\n" +
+				"test_seconds_sum 3.65\n" +
+				"test_seconds_count 4\n",
+		},
+		{
+			name:    "le is appended after the labels of the series",
+			buckets: []float64{0.5},
+			labels:  []string{"route", "method"},
+			observe: func(h *Histogram) {
+				h.Observe(0.25, "/loiter", "GET")
+				h.Observe(2, "/loiter", "GET")
+			},
+			want: "# HELP test_seconds Test histogram.\n" +
+				"# TYPE test_seconds histogram\n" +
+				`test_seconds_bucket{route="/loiter",method="GET",le="0.5"} 1` + "\n" +
+				`test_seconds_bucket{route="/loiter",method="GET",le="+Inf"} 2` + "\n" +
+				`test_seconds_sum{route="/loiter",method="GET"} 2.25` + "\n" +
+				`test_seconds_count{route="/loiter",method="GET"} 2` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewHistogram("test_seconds", "Test histogram.", test.buckets, test.labels...)
+			test.observe(h)
+			if got := written(h); got != test.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHelpEscaping(t *testing.T) {
+	c := NewCounter("test_help_total", "Counts C:\\logs\nacross two lines.")
+	want := "# HELP test_help_total Counts C:\\\\logs\\nacross two lines.\n"
+	if got := written(c); !strings.HasPrefix(got, want) {
+		t.Errorf("got:\n%s\nwant prefix:\n%s", got, want)
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := NewCounter("test_mismatch_total", "Test counter.", "route")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic when the label values do not match the label names")
+		}
+	}()
+	c.Inc("GET /loiter", "200")
+}
+
+func TestWriteTextRunsCollectorsFirst(t *testing.T) {
+	g := NewGauge("test_collected", "Set by a collector.")
+	RegisterCollector(func() { g.Set(42) })
+
+	var text strings.Builder
+	WriteText(&text)
+	if !strings.Contains(text.String(), "\ntest_collected 42\n") {
+		t.Errorf("collector value missing from:\n%s", text.String())
+	}
+}
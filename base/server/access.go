@@ -0,0 +1,74 @@
+// File: `Server Access Log File` base/server/access.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Incoming request IDs longer than this are replaced since they end up in every log line
+const maxRequestIDLength int = 128
+
+// Assigns every request an X-Request-ID (or keeps the one sent by the client / proxy) that is echoed in the
+// response & carried by the request context | Writes one access log entry once the request is served
+func requestLogging(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(PhoeniciaDigitalUtils.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(PhoeniciaDigitalUtils.RequestIDHeader, id)
+
+		ctx := PhoeniciaDigitalUtils.WithRequestID(r.Context(), id)
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		// Deferred so a response aborted with http.ErrAbortHandler (ex: a failed export) is still logged
+		defer func() {
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+
+			PhoeniciaDigitalUtils.Logger.LogAttrs(ctx, slog.LevelInfo, "HTTP request",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", recorder.status),
+				slog.Int64("bytes", recorder.bytes),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("remote", r.RemoteAddr),
+			)
+		}()
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+	})
+}
+
+// Only short printable IDs are propagated so a client can not inject anything into the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,14 @@
+// File: `Config Endpoint File` base/server/config.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"net/http"
+)
+
+// The effective config | every value with its env name & whether it came from a default, the config file, the
+// environment or a flag | Passwords, the JWT secret & the API keys are redacted
+func HandleConfig(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: PhoeniciaDigitalConfig.Describe()}
+}
@@ -0,0 +1,111 @@
+// File: `Server Health Checks File` base/server/health.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"Phoenicia-Digital-Base-API/source"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Component statuses | only `down` makes the API not ready
+const (
+	healthOK       string = "ok"
+	healthDown     string = "down"
+	healthDisabled string = "disabled"
+	healthIdle     string = "idle"
+)
+
+type componentHealth struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type readinessReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components"`
+}
+
+// Liveness | if the process can answer this it is alive
+func HandleHealthz(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: componentHealth{Status: healthOK}}
+}
+
+// Readiness | checks every database & the hardware returning 503 if any of them is down
+// Not ready is an answer of the probe not a failure of the call so the report is sent as is with its 503 instead of
+// an ApiError which LogResponses would log as an error on every probe while a backend is down
+func HandleReadyz(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	report := readinessReport{Status: healthOK, Components: checkComponents()}
+
+	for _, component := range report.Components {
+		if component.Status == healthDown {
+			report.Status = healthDown
+			return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusServiceUnavailable, Quote: report}
+		}
+	}
+
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: report}
+}
+
+// Run every component check | the databases are pinged in the background every DB_MONITOR_INTERVAL so a slow or
+// down database never delays the answer
+func checkComponents() map[string]componentHealth {
+	components := map[string]componentHealth{}
+
+	for _, status := range PhoeniciaDigitalDatabase.Statuses() {
+		switch {
+		case !status.Enabled:
+			components[status.Backend] = componentHealth{Status: healthDisabled}
+		case !status.Available:
+			components[status.Backend] = componentHealth{Status: healthDown, Detail: status.Error.Error()}
+		default:
+			components[status.Backend] = componentHealth{Status: healthOK}
+		}
+	}
+
+	if source.HCSR04.GPIOOpened() {
+		components["gpio"] = componentHealth{Status: healthOK}
+	} else {
+		components["gpio"] = componentHealth{Status: healthDown, Detail: "GPIO memory map is not open"}
+	}
+
+	if source.ServoMotor.Connected() {
+		components["servo"] = componentHealth{Status: healthOK}
+	} else {
+		components["servo"] = componentHealth{Status: healthDown, Detail: "Servo Motor is not connected"}
+	}
+
+	components["sensor"] = checkSensor()
+
+	return components
+}
+
+// The sensor only measures while clients are streaming so an old reading only means trouble if someone is streaming
+func checkSensor() componentHealth {
+	last, ok := source.HCSR04.LastReading()
+	streaming := source.HCSR04.ActiveStreams() > 0
+
+	switch {
+	case !ok && !streaming:
+		return componentHealth{Status: healthIdle, Detail: "No measurement taken yet"}
+	case !ok:
+		return componentHealth{Status: healthDown, Detail: "Clients are streaming but no measurement succeeded yet"}
+	}
+
+	// The sensor is considered stuck if no measurement succeeded for HEALTH_SENSOR_MAX_AGE while clients are streaming
+	maxAge := PhoeniciaDigitalConfig.Config().Health.Health_sensor_max_age
+
+	age := time.Since(last).Round(time.Millisecond)
+	detail := fmt.Sprintf("Last successful reading %s ago", age)
+	switch {
+	case age <= maxAge:
+		return componentHealth{Status: healthOK, Detail: detail}
+	case streaming:
+		return componentHealth{Status: healthDown, Detail: detail}
+	default:
+		return componentHealth{Status: healthIdle, Detail: detail}
+	}
+}
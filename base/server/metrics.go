@@ -0,0 +1,131 @@
+// File: `Server Metrics File` base/server/metrics.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalMetrics "Phoenicia-Digital-Base-API/base/metrics"
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequests = PhoeniciaDigitalMetrics.NewCounter("pd_http_requests_total", "Number of HTTP requests by route, method & status code.", "route", "method", "code")
+
+	httpDuration = PhoeniciaDigitalMetrics.NewHistogram("pd_http_request_duration_seconds", "Time taken to serve HTTP requests by route & method.",
+		PhoeniciaDigitalMetrics.DefaultBuckets, "route", "method")
+
+	dbOpenConnections  = PhoeniciaDigitalMetrics.NewGauge("pd_db_open_connections", "Number of open database connections by backend.", "backend")
+	dbInUseConnections = PhoeniciaDigitalMetrics.NewGauge("pd_db_in_use_connections", "Number of database connections currently in use by backend.", "backend")
+	dbIdleConnections  = PhoeniciaDigitalMetrics.NewGauge("pd_db_idle_connections", "Number of idle database connections by backend.", "backend")
+	dbUp               = PhoeniciaDigitalMetrics.NewGauge("pd_db_up", "1 if the database answered the last check of the monitor | 0 if it is down or not connected yet (only enabled databases are reported).", "backend")
+	dbWaits            = PhoeniciaDigitalMetrics.NewGauge("pd_db_wait_count", "Number of times a connection had to be waited for (Postgres) or timed out (Redis) or failed to check out (Mongo).", "backend")
+)
+
+// Wraps the http.ResponseWriter to remember the status code & the number of bytes written by the handler
+// Hijack & Flush are passed through so websockets & streamed exports keep working
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += int64(n)
+	return n, err
+}
+
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := sr.ResponseWriter.(http.Hijacker); ok {
+		// A hijacked connection is a websocket upgrade
+		sr.status = http.StatusSwitchingProtocols
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("http.Hijacker not implemented by the underlying ResponseWriter")
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// Records the count & latency of every request by the route pattern it matched on the multiplexer
+func instrumentHandler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		// Deferred so a handler aborting its response with http.ErrAbortHandler is still counted
+		defer func() {
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			httpRequests.Inc(route, r.Method, strconv.Itoa(recorder.status))
+			httpDuration.Observe(time.Since(start).Seconds(), route, r.Method)
+		}()
+		mux.ServeHTTP(recorder, r)
+	})
+}
+
+// Read the connection pool statistics of every connected database right before a scrape | a scrape never connects one
+func collectDatabaseStats() {
+	for _, status := range PhoeniciaDigitalDatabase.Statuses() {
+		if !status.Enabled {
+			continue
+		}
+		if status.Available {
+			dbUp.Set(1, status.Backend)
+		} else {
+			dbUp.Set(0, status.Backend)
+		}
+	}
+
+	if stats, connected := PhoeniciaDigitalDatabase.PostgresStats(); connected {
+		dbOpenConnections.Set(float64(stats.OpenConnections), "postgres")
+		dbInUseConnections.Set(float64(stats.InUse), "postgres")
+		dbIdleConnections.Set(float64(stats.Idle), "postgres")
+		dbWaits.Set(float64(stats.WaitCount), "postgres")
+	}
+
+	if stats, connected := PhoeniciaDigitalDatabase.MongoPoolStatistics(); connected {
+		dbOpenConnections.Set(float64(stats.OpenConnections), "mongo")
+		dbInUseConnections.Set(float64(stats.InUseConnections), "mongo")
+		dbIdleConnections.Set(float64(stats.OpenConnections-stats.InUseConnections), "mongo")
+		dbWaits.Set(float64(stats.CheckOutFailures), "mongo")
+	}
+
+	if stats, connected := PhoeniciaDigitalDatabase.RedisPoolStats(); connected {
+		dbOpenConnections.Set(float64(stats.TotalConns), "redis")
+		dbInUseConnections.Set(float64(stats.TotalConns-stats.IdleConns), "redis")
+		dbIdleConnections.Set(float64(stats.IdleConns), "redis")
+		dbWaits.Set(float64(stats.Timeouts), "redis")
+	}
+}
+
+func init() {
+	PhoeniciaDigitalMetrics.RegisterCollector(collectDatabaseStats)
+}
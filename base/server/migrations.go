@@ -0,0 +1,55 @@
+// File: `Migration Endpoints File` base/server/migrations.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// The same actions as `main migrate` | every run holds the migration lock so they are safe with several instances
+
+// Answer a migration call | a disabled Postgres is a 503 & anything else a 500 (the error names the failing migration)
+func migrationResponse(result any, err error) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	if errors.Is(err, PhoeniciaDigitalDatabase.ErrDisabled) {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusServiceUnavailable, Quote: err.Error()}
+	}
+	if err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: err.Error()}
+	}
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: result}
+}
+
+// Every migration & whether it is applied
+func HandleMigrationStatus(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	return migrationResponse(PhoeniciaDigitalDatabase.MigrationStatus(r.Context()))
+}
+
+// Apply every pending migration | returns the ones applied
+func HandleMigrateUp(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	return migrationResponse(PhoeniciaDigitalDatabase.MigrateUp(r.Context()))
+}
+
+// Revert the last ?steps= applied migrations (defaults to 1) | returns the ones reverted
+func HandleMigrateDown(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	steps := 1
+	if value := r.URL.Query().Get("steps"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: "steps must be a whole number >= 1 got: " + value}
+		}
+		steps = parsed
+	}
+	return migrationResponse(PhoeniciaDigitalDatabase.MigrateDown(r.Context(), steps))
+}
+
+// Apply or revert migrations until the ones up to {version} are applied | 0 reverts every migration
+func HandleMigrateTo(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	version, err := strconv.ParseInt(r.PathValue("version"), 10, 64)
+	if err != nil || version < 0 {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: "version must be a whole number >= 0 got: " + r.PathValue("version")}
+	}
+	return migrationResponse(PhoeniciaDigitalDatabase.MigrateTo(r.Context(), version))
+}
@@ -0,0 +1,61 @@
+// File: `Server Rate Limits File` base/server/ratelimit.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalAuth "Phoenicia-Digital-Base-API/base/auth"
+	PhoeniciaDigitalMetrics "Phoenicia-Digital-Base-API/base/metrics"
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"net"
+	"net/http"
+)
+
+var rateLimited = PhoeniciaDigitalMetrics.NewCounter("pd_http_rate_limited_total", "Number of requests refused with 429 by route.", "route")
+
+// Routes polled by probes & scrapers (kubelet, docker, prometheus) often from a single IP are never limited | a 429
+// there would mark a healthy device as down | GET /metrics is registered without `handle` so it is never limited either
+var unlimitedRoutes = map[string]bool{
+	"GET /healthz": true,
+	"GET /readyz":  true,
+}
+
+// Requests are limited per API key (or JWT subject) once authenticated & per IP address otherwise
+func rateLimitKey(r *http.Request) string {
+	if principal, ok := PhoeniciaDigitalAuth.PrincipalFrom(r.Context()); ok {
+		return principal.Method + ":" + principal.Subject
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// The rate limit middleware of a route | added by `handle` after the route's own middleware so the
+// authenticated client is known | Returns an ApiError 429 with Retry-After once the client's bucket is empty
+func rateLimit(pattern string) PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware {
+	settings := PhoeniciaDigitalConfig.Config().RateLimit
+	if !settings.Rate_limit_enabled || unlimitedRoutes[pattern] {
+		return func(next PhoeniciaDigitalUtils.PhoeniciaDigitalHandler) PhoeniciaDigitalUtils.PhoeniciaDigitalHandler {
+			return next
+		}
+	}
+
+	// Routes without their own entry in RATE_LIMIT_ROUTES use RATE_LIMIT_DEFAULT
+	limit, ok := settings.Rate_limit_routes[pattern]
+	if !ok {
+		limit = settings.Rate_limit_default
+	}
+	limiter := PhoeniciaDigitalUtils.NewRateLimiter(limit)
+
+	return func(next PhoeniciaDigitalUtils.PhoeniciaDigitalHandler) PhoeniciaDigitalUtils.PhoeniciaDigitalHandler {
+		return func(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+			if allowed, retryAfter := limiter.Allow(rateLimitKey(r)); !allowed {
+				rateLimited.Inc(pattern)
+				return PhoeniciaDigitalUtils.TooManyRequests(w, &PhoeniciaDigitalUtils.RateLimitError{Limit: pattern, RetryAfter: retryAfter})
+			}
+			return next(w, r)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// File: `Server Config Reload File` base/server/reload.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"Phoenicia-Digital-Base-API/source"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// How often the modification time of the config files is checked
+const configPollInterval time.Duration = 2 * time.Second
+
+// Reload the config whenever ./config/.env or the config file changes or the process receives SIGHUP (kill -HUP <pid>)
+// Only the file can change while running | the real environment of a running process is fixed at its start
+func watchConfig() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	modified := configModified()
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hangup:
+			PhoeniciaDigitalUtils.Logger.Info("Received SIGHUP | Reloading config")
+		case <-ticker.C:
+			latest := configModified()
+			if !latest.After(modified) {
+				continue
+			}
+			modified = latest
+			PhoeniciaDigitalUtils.Logger.Info("Config file changed | Reloading config", "files", PhoeniciaDigitalConfig.Files())
+		}
+
+		reloadConfig()
+	}
+}
+
+// The newest modification time of the config files | files that do not exist are skipped
+func configModified() time.Time {
+	latest := time.Time{}
+	for _, file := range PhoeniciaDigitalConfig.Files() {
+		if stat, err := os.Stat(file); err == nil && stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+	return latest
+}
+
+// Reload & re-validate the config then hand the applied changes to the parts of the API that use them
+// Changes that need a restart (pins, ports, databases, ...) are logged & ignored until the next start
+func reloadConfig() {
+	applied, rejected, err := PhoeniciaDigitalConfig.Reload()
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.Error("Config reload rejected | Keeping the current config", "error", strings.ReplaceAll(err.Error(), "\n", " | "))
+		return
+	}
+
+	for _, name := range rejected {
+		PhoeniciaDigitalUtils.Logger.Warn("Config change needs a restart | Ignored until the API is restarted", "value", name)
+	}
+
+	if len(applied) == 0 {
+		PhoeniciaDigitalUtils.Logger.Info("Config reloaded | No change applied")
+		return
+	}
+
+	changed := map[string]bool{}
+	for _, name := range applied {
+		changed[strings.SplitN(name, ".", 2)[0]] = true
+	}
+
+	if changed["Pins"] || changed["RateLimit"] {
+		source.ServoMotor.Reconfigure()
+	}
+
+	if changed["Cors"] {
+		PhoeniciaDigitalUtils.CORS.Reload()
+	}
+
+	if changed["Logging"] {
+		if level, err := PhoeniciaDigitalUtils.ParseLevel(PhoeniciaDigitalConfig.Config().Logging.Log_level); err == nil {
+			PhoeniciaDigitalUtils.SetLevel(level)
+		}
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Config reloaded", "applied", strings.Join(applied, ", "))
+}
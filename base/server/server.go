@@ -2,82 +2,126 @@
 package PhoeniciaDigitalServer
 
 import (
+	PhoeniciaDigitalAuth "Phoenicia-Digital-Base-API/base/auth"
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalMetrics "Phoenicia-Digital-Base-API/base/metrics"
 	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
 	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
 	"Phoenicia-Digital-Base-API/source"
+	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 )
 
 // Initialize Server Ecosystem Variables
 var multiplexer *http.ServeMux = http.NewServeMux()
 
 var PhoeniciaDigitalServer *http.Server = &http.Server{
-	Addr:    PhoeniciaDigitalConfig.Config.Port,
-	Handler: multiplexer,
+	Addr:    fmt.Sprintf(":%d", PhoeniciaDigitalConfig.Config().Port),
+	Handler: requestLogging(multiplexer, PhoeniciaDigitalUtils.CORS.Handler(instrumentHandler(multiplexer))),
 }
 
+// Middleware applied to every route registered with `handle` | The first one is the outermost
+var globalMiddleware []PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware = []PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware{
+	PhoeniciaDigitalUtils.LogResponses,
+}
+
+// Register a `PhoeniciaDigitalHandler` on the multiplexer wrapped by the global middleware followed by the
+// route's own middleware & the route's rate limit ex: handle("GET /loiter", source.HandleLoiter, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.MoveServo))
+func handle(pattern string, handler PhoeniciaDigitalUtils.PhoeniciaDigitalHandler, middleware ...PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware) {
+	chain := append(append([]PhoeniciaDigitalUtils.PhoeniciaDigitalMiddleware{}, globalMiddleware...), middleware...)
+	chain = append(chain, rateLimit(pattern))
+	multiplexer.Handle(pattern, PhoeniciaDigitalUtils.Chain(handler, chain...))
+}
+
+// Serve HTTP or HTTPS (TLS_ENABLED) on PORT | the port is validated when the config is loaded
 func StartServer() {
-	if PhoeniciaDigitalServer.Addr != ":" {
-		if portNumber, err := strconv.Atoi(PhoeniciaDigitalServer.Addr[1:]); err != nil {
-			log.Printf("Given PORT is Invalid: %s != int | Change in ./config/.env", PhoeniciaDigitalServer.Addr[1:])
-			PhoeniciaDigitalUtils.Log(fmt.Sprintf("Given PORT is Invalid: %s != int | Change in ./config/.env", PhoeniciaDigitalServer.Addr[1:]))
-		} else {
-			if portNumber >= 0 && portNumber <= 65535 {
-				log.Printf("Server Running on http://localhost%s", PhoeniciaDigitalServer.Addr)
-				PhoeniciaDigitalUtils.Log(fmt.Sprintf("Server started on PORT --> %s", PhoeniciaDigitalServer.Addr))
-				log.Fatal(PhoeniciaDigitalServer.ListenAndServe())
-			} else {
-				log.Printf("Given PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalServer.Addr[1:])
-				PhoeniciaDigitalUtils.Log(fmt.Sprintf("Given PORT: %s is OUT OF RANGE 0 --> 65535 | Change in ./config/.env", PhoeniciaDigitalServer.Addr[1:]))
-			}
+	port := PhoeniciaDigitalConfig.Config().Port
+	settings := PhoeniciaDigitalConfig.Config().TLS
+
+	go watchConfig()
+
+	// The query files built into the binary (or read from POSTGRES_SQL_DIR) | an unreadable folder stops the start
+	queries, err := PhoeniciaDigitalDatabase.QueryNames()
+	if err != nil {
+		PhoeniciaDigitalUtils.Fatal("Failed to list the query files | Verify POSTGRES_SQL_DIR in ./config/.env", "source", PhoeniciaDigitalDatabase.SQLSource(), "error", err)
+	}
+	PhoeniciaDigitalUtils.Logger.Info("SQL queries available", "source", PhoeniciaDigitalDatabase.SQLSource(), "queries", queries)
+
+	// Wait for the enabled databases (DB_CONNECT_*) then keep checking them for /readyz & reconnecting them
+	if err := PhoeniciaDigitalDatabase.Connect(context.Background()); err != nil {
+		PhoeniciaDigitalUtils.Fatal("Databases are not reachable | Verify their config values in ./config/.env", "error", err)
+	}
+	go PhoeniciaDigitalDatabase.Monitor(context.Background())
+
+	// POSTGRES_PREPARE_ON_START | checked to need POSTGRES_ENABLED while the config is loaded
+	if PhoeniciaDigitalConfig.Config().Postgres.Postgres_prepare_on_start {
+		if err := PhoeniciaDigitalDatabase.Postgres.PrepareAll(); err != nil {
+			PhoeniciaDigitalUtils.Fatal("Invalid query files | Fix them or run `main migrate` when their tables are missing", "error", err)
 		}
-	} else {
-		log.Printf("Given PORT is empty | Change in ./config/.env")
-		PhoeniciaDigitalUtils.Log("Given PORT is empty | Change in ~/config/.env")
 	}
+
+	if !settings.Tls_enabled {
+		PhoeniciaDigitalUtils.Logger.Info("Server Running", "url", fmt.Sprintf("http://localhost%s", PhoeniciaDigitalServer.Addr), "port", port)
+		PhoeniciaDigitalUtils.Fatal("Server stopped", "error", PhoeniciaDigitalServer.ListenAndServe())
+	}
+
+	tlsConfig, err := newTLSConfig()
+	if err != nil {
+		PhoeniciaDigitalUtils.Fatal("Failed to configure TLS | Verify TLS_* values in ./config/.env", "error", err)
+	}
+	PhoeniciaDigitalServer.TLSConfig = tlsConfig
+
+	if settings.Tls_redirect_port != 0 {
+		go redirectToHTTPS(settings.Tls_redirect_port, port)
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Server Running", "url", fmt.Sprintf("https://localhost%s", PhoeniciaDigitalServer.Addr), "port", port, "mtls", settings.Tls_client_ca_file != "")
+	PhoeniciaDigitalUtils.Fatal("Server stopped", "error", PhoeniciaDigitalServer.ListenAndServeTLS("", ""))
 }
 
 // Initialize Server Logic
+// Admin routes (calibration & config) must be registered with requireClientCertificate before their
+// PhoeniciaDigitalAuth.Permit so TLS_CLIENT_CA_FILE enforces mTLS on them
 func init() {
-	multiplexer.HandleFunc("/sensor", source.HandleMeasureDistance)
-
-	multiplexer.HandleFunc("OPTIONS /loiter", func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers for all requests (can be more specific if needed)
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3001") // Allow requests from any origin (http://localhost:3000 in your case)
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	})
-	multiplexer.Handle("GET /loiter", PhoeniciaDigitalUtils.PhoeniciaDigitalHandler(source.HandleLoiter))
-
-	multiplexer.HandleFunc("OPTIONS /rotate-right", func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers for all requests (can be more specific if needed)
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3001") // Allow requests from any origin (http://localhost:3000 in your case)
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	})
-	multiplexer.Handle("GET /rotate-right", PhoeniciaDigitalUtils.PhoeniciaDigitalHandler(source.HandleRotateRight))
-
-	multiplexer.HandleFunc("OPTIONS /rotate-left", func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers for all requests (can be more specific if needed)
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3001") // Allow requests from any origin (http://localhost:3000 in your case)
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	})
-	multiplexer.Handle("GET /rotate-left", PhoeniciaDigitalUtils.PhoeniciaDigitalHandler(source.HandleRotateLeft))
-
-	// multiplexer.HandleFunc("OPTIONS /sensor", func(w http.ResponseWriter, r *http.Request) {
-	// 	// Set CORS headers for all requests (can be more specific if needed)
-	// 	w.Header().Set("Access-Control-Allow-Origin", "*") // Allow requests from any origin (http://localhost:3000 in your case)
-	// 	w.Header().Set("Access-Control-Allow-Credentials", "true")
-	// 	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	// 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-	// })
+	// Websocket handshakes authenticate with a header or ?token= before the upgrade
+	multiplexer.HandleFunc("/sensor", PhoeniciaDigitalAuth.PermitHTTP(PhoeniciaDigitalAuth.ReadSensor, source.HandleMeasureDistance))
+
+	// Prometheus scrape endpoint
+	multiplexer.Handle("GET /metrics", PhoeniciaDigitalMetrics.Handler())
+
+	// Liveness & Readiness checks for docker-compose & monitoring
+	handle("GET /healthz", HandleHealthz)
+	handle("GET /readyz", HandleReadyz)
+
+	// Servo motion | preflight requests are answered by the CORS policy so no OPTIONS handlers are needed
+	// Every route that moves the servo must be registered with PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.MoveServo)
+	handle("GET /loiter", source.HandleLoiter, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.MoveServo))
+	handle("GET /rotate-right", source.HandleRotateRight, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.MoveServo))
+	handle("GET /rotate-left", source.HandleRotateLeft, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.MoveServo))
+
+	// Session recording & replay | replays are served over the same websocket protocol as /sensor
+	handle("POST /recordings/start", source.HandleStartRecording, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.RecordSessions))
+	handle("POST /recordings/stop", source.HandleStopRecording, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.RecordSessions))
+	handle("GET /recordings", source.HandleListRecordings, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ReadState))
+	multiplexer.HandleFunc("/recordings/{name}/replay", PhoeniciaDigitalAuth.PermitHTTP(PhoeniciaDigitalAuth.ReadState, source.HandleReplayRecording))
+
+	// Exports of the stored readings / scan frames (of `main scan`) as CSV or NDJSON
+	multiplexer.HandleFunc("GET /export/readings", PhoeniciaDigitalAuth.PermitHTTP(PhoeniciaDigitalAuth.ReadState, source.HandleExportReadings))
+	multiplexer.HandleFunc("GET /export/scans", PhoeniciaDigitalAuth.PermitHTTP(PhoeniciaDigitalAuth.ReadState, source.HandleExportScans))
+
+	// Calibration | the values the servo & sensor run with & admins applying new ones (found with `main calibrate`)
+	handle("GET /calibration", source.HandleCalibration, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ReadState))
+	handle("PUT /calibration", source.HandleSetCalibration, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.WriteCalibration))
+
+	// Admin | the effective config with its sources & the secrets redacted
+	handle("GET /config", HandleConfig, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ReadConfig))
+
+	// Admin | the migrations of sql/migrations like `main migrate`
+	handle("GET /migrations", HandleMigrationStatus, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ManageMigrations))
+	handle("POST /migrations/up", HandleMigrateUp, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ManageMigrations))
+	handle("POST /migrations/down", HandleMigrateDown, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ManageMigrations))
+	handle("POST /migrations/to/{version}", HandleMigrateTo, requireClientCertificate, PhoeniciaDigitalAuth.Permit(PhoeniciaDigitalAuth.ManageMigrations))
 
 	// multiplexer.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
 	// 	fmt.Fprintln(w, "Hello, world!")
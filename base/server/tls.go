@@ -0,0 +1,265 @@
+// File: `Server TLS File` base/server/tls.go
+package PhoeniciaDigitalServer
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How often the certificate & key files are checked for changes
+const certificatePollInterval time.Duration = 10 * time.Second
+
+// certificateReloader serves the certificate through tls.Config.GetCertificate & reloads it once the
+// certificate or key file changes on disk (ex: after a certbot renewal) without restarting the server
+// A failed reload keeps serving the previous certificate
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	modified    time.Time
+
+	// Closed by Stop once the server shuts down to end the watch
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newCertificateReloader(certFile string, keyFile string) (*certificateReloader, error) {
+	cr := &certificateReloader{certFile: certFile, keyFile: keyFile, stop: make(chan struct{})}
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+	go cr.watch()
+	return cr, nil
+}
+
+// The newest modification time of the certificate & key files
+func (cr *certificateReloader) lastModified() (time.Time, error) {
+	latest := time.Time{}
+	for _, file := range []string{cr.certFile, cr.keyFile} {
+		stat, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (cr *certificateReloader) load() error {
+	modified, err := cr.lastModified()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cr.mu.Lock()
+	cr.certificate = &certificate
+	cr.modified = modified
+	cr.mu.Unlock()
+	return nil
+}
+
+// Stop ending the watch of the certificate files | safe to call more than once
+func (cr *certificateReloader) Stop() {
+	cr.stopOnce.Do(func() { close(cr.stop) })
+}
+
+func (cr *certificateReloader) watch() {
+	ticker := time.NewTicker(certificatePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cr.stop:
+			return
+		case <-ticker.C:
+		}
+
+		modified, err := cr.lastModified()
+		if err != nil {
+			PhoeniciaDigitalUtils.Logger.Warn("Failed to check TLS certificate for changes", "cert_file", cr.certFile, "key_file", cr.keyFile, "error", err)
+			continue
+		}
+
+		cr.mu.RLock()
+		changed := modified.After(cr.modified)
+		cr.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := cr.load(); err != nil {
+			// The files may be mid write | the next poll retries since the modification time was not recorded
+			PhoeniciaDigitalUtils.Logger.Error("Failed to reload TLS certificate | Still serving the previous one", "cert_file", cr.certFile, "error", err)
+			continue
+		}
+		PhoeniciaDigitalUtils.Logger.Info("Reloaded TLS certificate", "cert_file", cr.certFile)
+	}
+}
+
+func (cr *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.certificate, nil
+}
+
+// Build the tls.Config of the server | client certificates are requested but only verified if given
+// so regular routes keep working without one while `requireClientCertificate` guards the admin routes
+func newTLSConfig() (*tls.Config, error) {
+	settings := PhoeniciaDigitalConfig.Config().TLS
+	if settings.Tls_self_signed {
+		if _, err := os.Stat(settings.Tls_cert_file); errors.Is(err, os.ErrNotExist) {
+			if err := GenerateSelfSignedCertificate(settings.Tls_cert_file, settings.Tls_key_file, nil); err != nil {
+				return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+			}
+			PhoeniciaDigitalUtils.Logger.Warn("Generated a self-signed TLS certificate | Only use it for development", "cert_file", settings.Tls_cert_file, "key_file", settings.Tls_key_file)
+		}
+	}
+
+	reloader, err := newCertificateReloader(settings.Tls_cert_file, settings.Tls_key_file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	PhoeniciaDigitalServer.RegisterOnShutdown(reloader.Stop)
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if settings.Tls_client_ca_file != "" {
+		data, err := os.ReadFile(settings.Tls_client_ca_file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS_CLIENT_CA_FILE: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("TLS_CLIENT_CA_FILE holds no PEM certificates")
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// requireClientCertificate is the middleware of the admin routes when TLS_CLIENT_CA_FILE is set (mTLS)
+// Requests without a verified client certificate get an ApiError 403 | does nothing when mTLS is not configured
+func requireClientCertificate(next PhoeniciaDigitalUtils.PhoeniciaDigitalHandler) PhoeniciaDigitalUtils.PhoeniciaDigitalHandler {
+	return func(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+		if PhoeniciaDigitalServer.TLSConfig == nil || PhoeniciaDigitalServer.TLSConfig.ClientCAs == nil {
+			return next(w, r)
+		}
+
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			return PhoeniciaDigitalUtils.ApiError{Code: http.StatusForbidden, Quote: "a client certificate is required for this route"}
+		}
+		return next(w, r)
+	}
+}
+
+// Serve plain HTTP on the redirect port sending every request to the same path over HTTPS
+func redirectToHTTPS(redirectPort int, httpsPort int) {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", redirectPort),
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = r.Host
+			}
+			if httpsPort != 443 {
+				host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+			}
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+		}),
+	}
+
+	PhoeniciaDigitalUtils.Logger.Info("Redirecting HTTP to HTTPS", "port", redirectPort, "https_port", httpsPort)
+	PhoeniciaDigitalUtils.Fatal("HTTP redirect listener stopped", "error", server.ListenAndServe())
+}
+
+// GenerateSelfSignedCertificate writes an ECDSA P-256 certificate valid for a year for localhost & the given hosts
+// (IP addresses or DNS names) | Browsers will warn about it so it is only meant for first-run development
+func GenerateSelfSignedCertificate(certFile string, keyFile string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{PhoeniciaDigitalConfig.Config().Project_Name + " Development"}, CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey}), 0600)
+}
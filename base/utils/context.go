@@ -0,0 +1,44 @@
+// File: `Server Request Context File` source/utils/context.go
+package PhoeniciaDigitalUtils
+
+import (
+	"context"
+	"log/slog"
+)
+
+// The header a request ID is read from & echoed back in
+const RequestIDHeader string = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// Returns a copy of ctx carrying the request ID | every log line emitted with that ctx includes it
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Returns the request ID carried by ctx | false if ctx does not belong to a request
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// contextHandler adds the request ID of the context to every record logged through the *Context methods
+// ex: PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "Rotated Servo", "degree", degree)
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
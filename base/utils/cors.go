@@ -0,0 +1,142 @@
+// File: `Server CORS Policy File` source/utils/cors.go
+package PhoeniciaDigitalUtils
+
+import (
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The single CORS policy of the API | applied to every route by `Handler` & to websocket upgrades by `CheckOrigin`
+type corsPolicy struct {
+	mu          sync.RWMutex // the policy is swapped in place on a config reload
+	origins     []string     // exact origins or path.Match patterns ex: https://*.example.com | * allows any origin
+	methods     string
+	headers     string
+	credentials bool
+	maxAge      int // seconds a browser may cache a preflight response
+}
+
+var CORS *corsPolicy
+
+// Read the CORS_* values into the policy | patterns are validated when the config is loaded
+// Called again after a config reload so origin changes apply without a restart
+func (c *corsPolicy) Reload() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	settings := PhoeniciaDigitalConfig.Config().Cors
+	c.origins = append([]string{}, settings.Cors_origins...)
+	c.methods = strings.Join(settings.Cors_methods, ", ")
+	c.headers = strings.Join(settings.Cors_headers, ", ")
+	c.credentials = settings.Cors_credentials
+	c.maxAge = settings.Cors_max_age
+}
+
+// Reports if the origin is allowed by the policy
+func (c *corsPolicy) AllowOrigin(origin string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.allowOrigin(origin)
+}
+
+// The caller must hold c.mu
+func (c *corsPolicy) allowOrigin(origin string) bool {
+	for _, allowed := range c.origins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		if matched, _ := path.Match(allowed, origin); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports if CORS_ORIGINS holds * | the caller must hold c.mu
+func (c *corsPolicy) anyOrigin() bool {
+	for _, allowed := range c.origins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// The caller must hold c.mu
+func (c *corsPolicy) allowMethod(method string) bool {
+	for _, allowed := range strings.Split(c.methods, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), method) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin is used by the websocket upgraders | requests without an Origin (non browser clients) are allowed
+func (c *corsPolicy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || c.AllowOrigin(origin)
+}
+
+// Handler applies the policy to every request & answers every preflight request itself so
+// no route needs its own OPTIONS handler | Requests from origins not allowed get no CORS headers
+func (c *corsPolicy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if preflight := c.applyHeaders(w, r); preflight {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Set the CORS headers of the response | reports if the request is a preflight request the policy answers itself
+func (c *corsPolicy) applyHeaders(w http.ResponseWriter, r *http.Request) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	origin := r.Header.Get("Origin")
+	w.Header().Add("Vary", "Origin")
+
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	allowed := origin != "" && c.allowOrigin(origin)
+
+	if allowed && preflight && !c.allowMethod(r.Header.Get("Access-Control-Request-Method")) {
+		allowed = false
+	}
+
+	// * answers with a literal * & never allows credentials so no website gets credentialed access
+	// (CORS_ORIGINS=* with CORS_CREDENTIALS=true is also rejected when the config is loaded)
+	if allowed && c.anyOrigin() {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else if allowed {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if c.credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	if preflight {
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Methods", c.methods)
+			w.Header().Set("Access-Control-Allow-Headers", c.headers)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.maxAge))
+		}
+		return true
+	}
+
+	if allowed {
+		w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
+	}
+	return false
+}
+
+// Initializes the CORS policy from the CORS_* values in ./config/.env
+func init() {
+	CORS = &corsPolicy{}
+	CORS.Reload()
+}
@@ -78,7 +78,6 @@ func (apiScc ApiSuccess) Response() any {
 func SendJSON(w http.ResponseWriter, status int, val any) error {
 	// Set the Response Writers Header status and the content type to JSON so that we can send JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3001")
 	w.WriteHeader(status)
 
 	// Encode The Value `val` into the Response Writer and return an error if occured which will be managed by
@@ -87,12 +86,9 @@ func SendJSON(w http.ResponseWriter, status int, val any) error {
 }
 
 // Implementaion of the http.ServeHTTP interface on `PhoeniciaDigitalHandler`
+// Only encodes the response | CORS headers, logging & other cross-cutting concerns are middleware
+// attached with `Chain` (check middleware.go)
 func (pdf PhoeniciaDigitalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Set CORS headers
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3001")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	// Call the underlying handler function & Handle Responses | ERROR / NON-ERROR
 	if response := pdf(w, r); response != nil {
 		if _, converted := response.(ApiSuccess); converted {
@@ -105,7 +101,6 @@ func (pdf PhoeniciaDigitalHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 			}
 		} else if _, converted := response.(ApiError); converted {
 			if response.Status() != 0 || response.Response() != nil {
-				Log(response.Log())
 				if ierr := SendJSON(w, response.Status(), response); ierr != nil {
 					http.Error(w, response.Log(), response.Status())
 				}
@@ -114,7 +109,6 @@ func (pdf PhoeniciaDigitalHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 			}
 		}
 	} else {
-		Log("!!!CAUTION!!! `PhoeniciaDigitalResponse` nil RETURNED ON LAST API CALL")
 		if ierr := SendJSON(w, http.StatusInternalServerError, ApiError{Code: http.StatusInternalServerError, Quote: "!!!CAUTION!!! NO TYPE `PhoeniciaDigitalResponse` RETURNED ON LAST API CALL"}); ierr != nil {
 			http.Error(w, "!!!CAUTION!!! `PhoeniciaDigitalResponse` nil RETURNED ON LAST API CALL", http.StatusInternalServerError)
 		}
@@ -2,24 +2,107 @@
 package PhoeniciaDigitalUtils
 
 import (
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 )
 
-var _PhoeniciaDigitalLogger *log.Logger
+// The structured logger used through out the entire project | Use key / value pairs for the details
+// ex: PhoeniciaDigitalUtils.Logger.Error("Failed to connect to Servo Motor", "pin", motorPin, "error", err)
+var Logger *slog.Logger
 
-// InitLogger initializes the logger and sets the log output to the given log file.
+// The minimum level logged | a LevelVar so it can be changed while running
+var logLevel *slog.LevelVar = new(slog.LevelVar)
+
+// Parse a LOG_LEVEL value (debug, info, warn, error) | an empty value defaults to info
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level: %s | valid levels are debug, info, warn & error", value)
+	}
+}
+
+// Build the rotating log file from LOG_FILE, LOG_MAX_SIZE (MB), LOG_MAX_AGE, LOG_MAX_BACKUPS, LOG_MAX_ARCHIVE_AGE
+// & LOG_COMPRESS | Setting LOG_MAX_SIZE, LOG_MAX_AGE, LOG_MAX_BACKUPS or LOG_MAX_ARCHIVE_AGE to 0 disables that limit
+func logFile() (io.Writer, error) {
+	settings := PhoeniciaDigitalConfig.Config().Logging
+	return newRotatingFile(settings.Log_file, int64(settings.Log_max_size)*1024*1024, settings.Log_max_age, settings.Log_max_backups, settings.Log_max_archive_age, settings.Log_compress)
+}
+
+// Build the io.Writer the logs go to from LOG_OUTPUT (stderr, file, both) | stderr by default
+func logDestination() (io.Writer, error) {
+	output := PhoeniciaDigitalConfig.Config().Logging.Log_output
+	switch output {
+	case "stderr":
+		return os.Stderr, nil
+	case "file":
+		return logFile()
+	case "both":
+		file, err := logFile()
+		if err != nil {
+			return nil, err
+		}
+		return io.MultiWriter(os.Stderr, file), nil
+	default:
+		return nil, fmt.Errorf("invalid log output: %s | valid outputs are stderr, file & both", output)
+	}
+}
+
+// Initializes the structured logger from the LOG_* values in ./config/.env
 func init() {
-	logFile, err := os.OpenFile("./Phoenicia-Digital.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	settings := PhoeniciaDigitalConfig.Config().Logging
+
+	level, err := ParseLevel(settings.Log_level)
 	if err != nil {
-		log.Fatal("Error initializing logger:", err)
+		log.Fatal("Error initializing logger: ", err)
 	}
-	_PhoeniciaDigitalLogger = log.New(logFile, "- Time: ", log.Ldate|log.Ltime) // Include microseconds for more precision.
+	logLevel.Set(level)
+
+	destination, err := logDestination()
+	if err != nil {
+		log.Fatal("Error initializing logger: ", err)
+	}
+
+	options := &slog.HandlerOptions{Level: logLevel}
+	switch settings.Log_format {
+	case "text":
+		Logger = slog.New(contextHandler{slog.NewTextHandler(destination, options)})
+	case "json":
+		Logger = slog.New(contextHandler{slog.NewJSONHandler(destination, options)})
+	default:
+		log.Fatalf("Error initializing logger: invalid log format: %s | valid formats are text & json", settings.Log_format)
+	}
+
+	// Route the standard library & third party `log` output through the same logger
+	slog.SetDefault(Logger)
 }
 
-// Log logs the given message using the logger.
+// SetLevel changes the minimum level logged while running | used when LOG_LEVEL changes on a config reload
+func SetLevel(level slog.Level) {
+	logLevel.Set(level)
+}
+
+// Fatal logs the message at error level & exits the process | replaces log.Fatalf for unrecoverable errors
+func Fatal(message string, args ...any) {
+	Logger.Error(message, args...)
+	os.Exit(1)
+}
+
+// Log logs the given message at info level using the structured logger.
+//
+// Deprecated: use Logger.Info, Logger.Warn or Logger.Error with key / value pairs instead.
 func Log(message string) {
-	if _PhoeniciaDigitalLogger != nil {
-		_PhoeniciaDigitalLogger.Printf("| LOG: %s", message)
-	}
+	Logger.Info(message)
 }
@@ -0,0 +1,40 @@
+// File: `Server Middleware File` source/utils/middleware.go
+package PhoeniciaDigitalUtils
+
+import (
+	"net/http"
+)
+
+// A middleware wraps a `PhoeniciaDigitalHandler` seeing the request before it & the returned
+// `PhoeniciaDigitalResponse` after it | It can also return its own response without calling next
+// (ex: an ApiError 401 for authentication or 429 for rate limits)
+type PhoeniciaDigitalMiddleware func(next PhoeniciaDigitalHandler) PhoeniciaDigitalHandler
+
+// Chain wraps the handler with the given middleware | The first middleware is the outermost one
+// so Chain(h, a, b) runs a -> b -> h -> b -> a
+func Chain(handler PhoeniciaDigitalHandler, middleware ...PhoeniciaDigitalMiddleware) PhoeniciaDigitalHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// LogResponses logs every ApiError returned by the handler | Server side failures are logged as errors
+// while client mistakes are only warnings
+func LogResponses(next PhoeniciaDigitalHandler) PhoeniciaDigitalHandler {
+	return func(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalResponse {
+		response := next(w, r)
+
+		if response == nil {
+			Logger.ErrorContext(r.Context(), "!!!CAUTION!!! `PhoeniciaDigitalResponse` nil RETURNED ON LAST API CALL", "method", r.Method, "path", r.URL.Path)
+		} else if _, converted := response.(ApiError); converted {
+			if response.Status() >= http.StatusInternalServerError {
+				Logger.ErrorContext(r.Context(), "API call returned an error", "method", r.Method, "path", r.URL.Path, "status", response.Status(), "response", response.Log())
+			} else {
+				Logger.WarnContext(r.Context(), "API call returned an error", "method", r.Method, "path", r.URL.Path, "status", response.Status(), "response", response.Log())
+			}
+		}
+
+		return response
+	}
+}
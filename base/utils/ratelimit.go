@@ -0,0 +1,131 @@
+// File: `Server Rate Limiting File` source/utils/ratelimit.go
+package PhoeniciaDigitalUtils
+
+import (
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A TokenBucket allows bursts of up to `burst` calls & refills at `rate` tokens per second
+// Safe to use from concurrent goroutines
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Take a token if one is available | otherwise reports how long until the next one is
+func (tb *TokenBucket) Take() (bool, time.Duration) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return true, 0
+	}
+
+	if tb.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - tb.tokens) / tb.rate * float64(time.Second))
+}
+
+// Change the rate & burst of the bucket keeping the tokens it holds (capped to the new burst)
+// Used on a config reload so the change does not refill the bucket
+func (tb *TokenBucket) SetLimit(rate float64, burst int) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	// Tokens refilled so far are counted at the previous rate
+	now := time.Now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+
+	tb.rate, tb.burst = rate, float64(burst)
+	tb.tokens = math.Min(tb.burst, tb.tokens)
+}
+
+// Reports if the bucket is full meaning it has not been used for a while
+func (tb *TokenBucket) full() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.tokens+time.Since(tb.last).Seconds()*tb.rate >= tb.burst
+}
+
+// A RateLimit is a rate in tokens per second & the size of the bursts allowed on top of it | read from RATE_LIMIT_*
+type RateLimit = PhoeniciaDigitalConfig.RateLimit
+
+// A RateLimiter keeps one TokenBucket per client key (IP address or API key)
+// Buckets that refilled completely are dropped every sweep so idle clients do not accumulate
+type RateLimiter struct {
+	limit RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*TokenBucket
+	lastSweep time.Time
+}
+
+// Time between two sweeps of the idle buckets of a RateLimiter
+const rateLimiterSweep time.Duration = time.Minute
+
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{limit: limit, buckets: map[string]*TokenBucket{}, lastSweep: time.Now()}
+}
+
+// Take a token from the bucket of the client | reports how long until the next one when there is none
+func (rl *RateLimiter) Allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	if time.Since(rl.lastSweep) > rateLimiterSweep {
+		for client, bucket := range rl.buckets {
+			if bucket.full() {
+				delete(rl.buckets, client)
+			}
+		}
+		rl.lastSweep = time.Now()
+	}
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = NewTokenBucket(rl.limit.Rate, rl.limit.Burst)
+		rl.buckets[key] = bucket
+	}
+	rl.mu.Unlock()
+
+	return bucket.Take()
+}
+
+// Returned by anything that refuses work because a rate limit was exceeded
+type RateLimitError struct {
+	Limit      string // what was limited ex: the route or `servo motion`
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s | retry in %s", e.Limit, e.RetryAfter.Round(time.Millisecond))
+}
+
+// TooManyRequests sets the Retry-After header (whole seconds rounded up) & returns the ApiError 429 to respond with
+func TooManyRequests(w http.ResponseWriter, err *RateLimitError) ApiError {
+	seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	return ApiError{Code: http.StatusTooManyRequests, Quote: err.Error()}
+}
@@ -0,0 +1,284 @@
+// File: `Server Log Rotation File` source/utils/rotate.go
+package PhoeniciaDigitalUtils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The time format appended to rotated log files | sortable so the oldest archive is always first
+// Archives rotated within the same millisecond get a _001, _002, ... suffix which sorts after the first one
+const rotateTimeFormat string = "2006-01-02T15-04-05.000"
+
+// rotatingFile is an io.Writer over a log file that is rotated once it grows past maxSize bytes or gets older
+// than maxAge | rotated files are gzip compressed & only the newest maxBackups archives rotated less than
+// maxArchiveAge ago are kept
+// A zero maxSize, maxAge, maxBackups or maxArchiveAge disables that limit | Safe to use from concurrent goroutines
+type rotatingFile struct {
+	path          string
+	maxSize       int64
+	maxAge        time.Duration
+	maxBackups    int
+	maxArchiveAge time.Duration
+	compress      bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// Compression & pruning of archives runs in the background one at a time
+	millMu sync.Mutex
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int, maxArchiveAge time.Duration, compress bool) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, maxBackups: maxBackups, maxArchiveAge: maxArchiveAge, compress: compress}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
+
+// Decide if the next write of size bytes needs a fresh file | an empty file is never rotated
+func (rf *rotatingFile) shouldRotate(size int64) bool {
+	if rf.size == 0 {
+		return false
+	}
+	if rf.maxSize > 0 && rf.size+size > rf.maxSize {
+		return true
+	}
+	return rf.maxAge > 0 && time.Since(rf.openedAt) > rf.maxAge
+}
+
+// Open the log file in append mode creating the folder it lives in if needed | the caller must hold rf.mu
+func (rf *rotatingFile) open() error {
+	if dir := filepath.Dir(rf.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	// A file kept from before a restart ages from its last write so LOG_MAX_AGE still rotates a service that
+	// restarts more often than the limit
+	rf.file = file
+	rf.size = stat.Size()
+	rf.openedAt = time.Now()
+	if rf.size > 0 {
+		rf.openedAt = stat.ModTime()
+	}
+	return nil
+}
+
+// Move the current file aside as <name>-<time><ext> & start a new one | the caller must hold rf.mu
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	archive, err := rf.archiveName(time.Now())
+	if err == nil {
+		err = os.Rename(rf.path, archive)
+	}
+	if err != nil {
+		// Keep logging into the old file rather than losing messages
+		if openErr := rf.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	go rf.mill(archive)
+	return nil
+}
+
+// A name no archive uses yet (compressed or not) so two rotations within the same millisecond never overwrite
+// each other | the caller must hold rf.mu
+func (rf *rotatingFile) archiveName(now time.Time) (string, error) {
+	ext := filepath.Ext(rf.path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(rf.path, ext), now.Format(rotateTimeFormat))
+
+	for sequence := 0; sequence < 1000; sequence++ {
+		archive := base + ext
+		if sequence > 0 {
+			archive = fmt.Sprintf("%s_%03d%s", base, sequence, ext)
+		}
+		if !fileExists(archive) && !fileExists(archive+".gz") {
+			return archive, nil
+		}
+	}
+	return "", fmt.Errorf("too many log archives rotated at %s", now.Format(rotateTimeFormat))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// Compress the freshly rotated archive & remove the archives beyond maxBackups or rotated more than maxArchiveAge ago
+// Errors are written to stderr since the logger itself is what failed
+func (rf *rotatingFile) mill(archive string) {
+	rf.millMu.Lock()
+	defer rf.millMu.Unlock()
+
+	if rf.compress {
+		if err := compressFile(archive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compress log archive: %s | Error: %s\n", archive, err.Error())
+		}
+	}
+
+	if rf.maxBackups <= 0 && rf.maxArchiveAge <= 0 {
+		return
+	}
+
+	archives, err := rf.archives()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list log archives | Error: %s\n", err.Error())
+		return
+	}
+
+	// Oldest first so the archives past the age limit are a prefix of the list
+	expired := 0
+	if rf.maxArchiveAge > 0 {
+		for expired < len(archives) {
+			rotated, _ := rf.archiveTime(filepath.Base(archives[expired]))
+			if time.Since(rotated) <= rf.maxArchiveAge {
+				break
+			}
+			expired++
+		}
+	}
+	if rf.maxBackups > 0 && len(archives)-expired > rf.maxBackups {
+		expired = len(archives) - rf.maxBackups
+	}
+
+	for _, archive := range archives[:expired] {
+		if err := os.Remove(archive); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove log archive: %s | Error: %s\n", archive, err.Error())
+		}
+	}
+}
+
+// When an archive was rotated read from its file name | false for any name that is not exactly one written by
+// archiveName (<name>-<time>[_<sequence>]<ext>[.gz]) so pruning never touches other files sharing the folder
+func (rf *rotatingFile) archiveTime(name string) (time.Time, bool) {
+	ext := filepath.Ext(rf.path)
+	stamp, ok := strings.CutPrefix(name, filepath.Base(strings.TrimSuffix(rf.path, ext))+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	if stamp, ok = strings.CutSuffix(strings.TrimSuffix(stamp, ".gz"), ext); !ok {
+		return time.Time{}, false
+	}
+
+	stamp, sequence, sequenced := strings.Cut(stamp, "_")
+	if sequenced {
+		if number, err := strconv.Atoi(sequence); err != nil || number < 1 || len(sequence) != 3 {
+			return time.Time{}, false
+		}
+	}
+
+	rotated, err := time.ParseInLocation(rotateTimeFormat, stamp, time.Local)
+	if err != nil || rotated.Format(rotateTimeFormat) != stamp {
+		return time.Time{}, false
+	}
+	return rotated, true
+}
+
+// List the rotated archives of the log file oldest first
+func (rf *rotatingFile) archives() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(rf.path))
+	if err != nil {
+		return nil, err
+	}
+
+	archives := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, ok := rf.archiveTime(entry.Name()); ok {
+			archives = append(archives, filepath.Join(filepath.Dir(rf.path), entry.Name()))
+		}
+	}
+
+	sort.Strings(archives)
+	return archives, nil
+}
+
+// Gzip a file into <file>.gz removing the original once the archive is complete
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(destination)
+	if _, err := io.Copy(writer, source); err != nil {
+		destination.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		destination.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	if err := destination.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,176 @@
+// File: `Server Log Rotation Tests File` source/utils/rotate_test.go
+package PhoeniciaDigitalUtils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Create empty files in dir
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// The files left in dir sorted by name
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestArchiveName(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 26, 53, 589_000_000, time.Local)
+	stamp := now.Format(rotateTimeFormat)
+
+	tests := []struct {
+		name     string
+		existing []string
+		want     string
+	}{
+		{name: "first archive", want: "server-" + stamp + ".log"},
+		{name: "same millisecond as an archive", existing: []string{"server-" + stamp + ".log"}, want: "server-" + stamp + "_001.log"},
+		{name: "same millisecond as a compressed archive", existing: []string{"server-" + stamp + ".log.gz"}, want: "server-" + stamp + "_001.log"},
+		{
+			name:     "next free sequence",
+			existing: []string{"server-" + stamp + ".log.gz", "server-" + stamp + "_001.log.gz", "server-" + stamp + "_002.log"},
+			want:     "server-" + stamp + "_003.log",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			touch(t, dir, test.existing...)
+			rf := &rotatingFile{path: filepath.Join(dir, "server.log")}
+
+			got, err := rf.archiveName(now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filepath.Base(got) != test.want {
+				t.Errorf("archive = %s want %s", filepath.Base(got), test.want)
+			}
+			if _, ok := rf.archiveTime(filepath.Base(got)); !ok {
+				t.Errorf("%s is not recognized as an archive", filepath.Base(got))
+			}
+		})
+	}
+}
+
+func TestArchiveTime(t *testing.T) {
+	rf := &rotatingFile{path: filepath.Join("logs", "server.log")}
+	rotated := time.Date(2026, 3, 14, 9, 26, 53, 589_000_000, time.Local)
+	stamp := rotated.Format(rotateTimeFormat)
+
+	tests := []struct {
+		name      string
+		file      string
+		isArchive bool
+	}{
+		{name: "archive", file: "server-" + stamp + ".log", isArchive: true},
+		{name: "compressed archive", file: "server-" + stamp + ".log.gz", isArchive: true},
+		{name: "sequenced archive", file: "server-" + stamp + "_001.log.gz", isArchive: true},
+		{name: "the log file itself", file: "server.log"},
+		{name: "another log sharing the prefix", file: "server-access.log"},
+		{name: "a backup sharing the prefix", file: "server-old.log.gz"},
+		{name: "a date without the time", file: "server-2026-03-14.log"},
+		{name: "a different extension", file: "server-" + stamp + ".txt"},
+		{name: "a sequence that archiveName never writes", file: "server-" + stamp + "_1.log"},
+		{name: "a log with another name", file: "api-" + stamp + ".log"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := rf.archiveTime(test.file)
+			if ok != test.isArchive {
+				t.Fatalf("archiveTime(%s) archive = %v want %v", test.file, ok, test.isArchive)
+			}
+			if ok && !got.Equal(rotated) {
+				t.Errorf("archiveTime(%s) = %v want %v", test.file, got, rotated)
+			}
+		})
+	}
+}
+
+func TestMillPrunesOnlyArchives(t *testing.T) {
+	now := time.Now()
+	archive := func(age time.Duration) string {
+		return "server-" + now.Add(-age).Format(rotateTimeFormat) + ".log.gz"
+	}
+	oldest, older, newer, newest := archive(72*time.Hour), archive(48*time.Hour), archive(2*time.Hour), archive(time.Hour)
+	unrelated := []string{"server-access.log", "server-old.log.gz", "server.log", "notes.txt"}
+
+	tests := []struct {
+		name          string
+		maxBackups    int
+		maxArchiveAge time.Duration
+		want          []string
+	}{
+		{name: "no limits", want: []string{oldest, older, newer, newest}},
+		{name: "backups limit keeps the newest", maxBackups: 2, want: []string{newer, newest}},
+		{name: "age limit", maxArchiveAge: 24 * time.Hour, want: []string{newer, newest}},
+		{name: "the tighter limit wins", maxBackups: 1, maxArchiveAge: 24 * time.Hour, want: []string{newest}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			touch(t, dir, unrelated...)
+			touch(t, dir, oldest, older, newer, newest)
+			rf := &rotatingFile{path: filepath.Join(dir, "server.log"), maxBackups: test.maxBackups, maxArchiveAge: test.maxArchiveAge}
+
+			rf.mill(filepath.Join(dir, newest))
+
+			want := append(append([]string{}, unrelated...), test.want...)
+			sort.Strings(want)
+			if got := listFiles(t, dir); !reflect.DeepEqual(got, want) {
+				t.Errorf("files = %v want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMaxAgeSurvivesARestart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	if err := os.WriteFile(path, []byte("before the restart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lastWrite := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, lastWrite, lastWrite); err != nil {
+		t.Fatal(err)
+	}
+
+	rf, err := newRotatingFile(path, 0, time.Hour, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	if !rf.shouldRotate(1) {
+		t.Fatal("a file last written 2h ago is not rotated with a 1h max age after a restart")
+	}
+	if _, err := rf.Write([]byte("after the restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	if rf.shouldRotate(1) {
+		t.Error("the fresh file should not be rotated again")
+	}
+}
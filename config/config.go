@@ -2,104 +2,339 @@
 package PhoeniciaDigitalConfig
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"math"
 	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// The optional .env file | Variables already set in the environment (ex: by docker-compose) take precedence over it
+const envFile string = "./config/.env"
+
+// Every value is tagged with the `env` name it is read from (the flags & config file keys derive from it)
+// Values tagged `secret` are redacted whenever the config is shown
 type _PhoeniciaDigitalConfig struct {
-	Project_Name string
-	Port         string
+	Project_Name string `env:"PROJECT_NAME"`
+	Port         int    `env:"PORT"`
 	Postgres     postgres
 	Mongo        mongo
 	Redis        redis
+	Database     database
 	Pins         itepins
+	Calibration  calibration
+	Logging      logging
+	Cors         cors
+	Auth         auth
+	RateLimit    rateLimit
+	TLS          tls
+	Health       health
+	Alerts       alerts
+}
+
+type health struct {
+	Health_sensor_max_age time.Duration `env:"HEALTH_SENSOR_MAX_AGE"` // readiness fails if streaming clients got no reading for this long
+}
+
+// Raised by the sensor sampler & logged once when crossed | 0 disables a threshold
+type alerts struct {
+	Alert_distance      float64 `env:"ALERT_DISTANCE"`      // cm | a reading closer than this raises an alert
+	Alert_sensor_errors int     `env:"ALERT_SENSOR_ERRORS"` // failed measurements in a row that raise an alert
+}
+
+type tls struct {
+	Tls_enabled        bool   `env:"TLS_ENABLED"`
+	Tls_cert_file      string `env:"TLS_CERT_FILE"`
+	Tls_key_file       string `env:"TLS_KEY_FILE"`
+	Tls_client_ca_file string `env:"TLS_CLIENT_CA_FILE"` // when set the admin routes require a client certificate signed by this CA
+	Tls_redirect_port  int    `env:"TLS_REDIRECT_PORT"`  // 0 disables the HTTP -> HTTPS redirect listener
+	Tls_self_signed    bool   `env:"TLS_SELF_SIGNED"`
+}
+
+type rateLimit struct {
+	Rate_limit_enabled bool                 `env:"RATE_LIMIT_ENABLED"`
+	Rate_limit_default RateLimit            `env:"RATE_LIMIT_DEFAULT"`
+	Rate_limit_routes  map[string]RateLimit `env:"RATE_LIMIT_ROUTES"` // keyed by the route pattern ex: GET /rotate-right
+	Rate_limit_motion  RateLimit            `env:"RATE_LIMIT_MOTION"`
+}
+
+type auth struct {
+	Auth_enabled              bool     `env:"AUTH_ENABLED"`
+	Auth_api_keys             []string `env:"AUTH_API_KEYS" secret:"true"` // name:sha256hex:roles entries
+	Auth_api_keys_postgres    bool     `env:"AUTH_API_KEYS_POSTGRES"`
+	Auth_jwt_hs256_secret     string   `env:"AUTH_JWT_HS256_SECRET" secret:"true"`
+	Auth_jwt_rs256_public_key string   `env:"AUTH_JWT_RS256_PUBLIC_KEY"`
+	Auth_jwt_issuer           string   `env:"AUTH_JWT_ISSUER"`
+	Auth_jwt_audience         string   `env:"AUTH_JWT_AUDIENCE"`
+}
+
+type cors struct {
+	Cors_origins     []string `env:"CORS_ORIGINS"`
+	Cors_methods     []string `env:"CORS_METHODS"`
+	Cors_headers     []string `env:"CORS_HEADERS"`
+	Cors_credentials bool     `env:"CORS_CREDENTIALS"`
+	Cors_max_age     int      `env:"CORS_MAX_AGE"` // seconds
+}
+
+type logging struct {
+	Log_level           string        `env:"LOG_LEVEL"`
+	Log_format          string        `env:"LOG_FORMAT"`
+	Log_output          string        `env:"LOG_OUTPUT"`
+	Log_file            string        `env:"LOG_FILE"`
+	Log_max_size        int           `env:"LOG_MAX_SIZE"`        // MB | 0 disables the limit
+	Log_max_age         time.Duration `env:"LOG_MAX_AGE"`         // the log file is rotated once it is older
+	Log_max_backups     int           `env:"LOG_MAX_BACKUPS"`     // archives kept
+	Log_max_archive_age time.Duration `env:"LOG_MAX_ARCHIVE_AGE"` // archives rotated longer ago are removed
+	Log_compress        bool          `env:"LOG_COMPRESS"`
 }
 
 type itepins struct {
-	TriggerPin   string
-	EchoPin      string
-	MotorPin     string
-	RotateDegree string
-	LoiterSpeed  string
+	TriggerPin   int     `env:"TriggerPin"`
+	EchoPin      int     `env:"EchoPin"`
+	MotorPin     int     `env:"MotorPin"`
+	RotateDegree int     `env:"RotateDegree"`
+	LoiterSpeed  float64 `env:"LoiterSpeed"`
+	MoveSpeed    float64 `env:"MoveSpeed"` // speed of rotations, scans & calibration moves
+}
+
+// How serve waits for the enabled databases at startup & watches them once running
+type database struct {
+	Db_connect_initial_backoff time.Duration `env:"DB_CONNECT_INITIAL_BACKOFF"` // wait after the first failed attempt | doubled after every other one
+	Db_connect_max_backoff     time.Duration `env:"DB_CONNECT_MAX_BACKOFF"`     // cap of the wait between two attempts
+	Db_connect_max_wait        time.Duration `env:"DB_CONNECT_MAX_WAIT"`        // serve stops if a database is still down after this long | 0 tries once
+	Db_monitor_interval        time.Duration `env:"DB_MONITOR_INTERVAL"`        // how often the databases are pinged for /readyz
+}
+
+// Found with `calibrate servo` & `calibrate sensor` | the commands print the values to set
+type calibration struct {
+	Servo_trim   float64 `env:"SERVO_TRIM"`   // Degrees added to every angle sent to the servo so 90 points straight ahead
+	Sensor_scale float64 `env:"SENSOR_SCALE"` // factor applied to every distance measured by the sensor
 }
 
 type postgres struct {
-	Postgres_host     string
-	Postgres_port     string
-	Postgres_user     string
-	Postgres_password string
-	Postgres_db       string
-	Postgres_ssl      string
+	Postgres_enabled  bool   `env:"POSTGRES_ENABLED"`
+	Postgres_host     string `env:"POSTGRES_HOST"`
+	Postgres_port     int    `env:"POSTGRES_PORT"`
+	Postgres_user     string `env:"POSTGRES_USER"`
+	Postgres_password string `env:"POSTGRES_PASSWORD" secret:"true"`
+	Postgres_db       string `env:"POSTGRES_DB"`
+	Postgres_ssl      string `env:"POSTGRES_SSL"`
+
+	Postgres_sql_dir          string `env:"POSTGRES_SQL_DIR"`          // read the query files from this folder instead of the ones built into the binary
+	Postgres_prepare_on_start bool   `env:"POSTGRES_PREPARE_ON_START"` // serve prepares every query file before listening & stops on an invalid one
+	Postgres_sql_reload       bool   `env:"POSTGRES_SQL_RELOAD"`       // re-prepare a query once its .sql file changes | meant for development
+
+	Postgres_query_timeout  time.Duration            `env:"POSTGRES_QUERY_TIMEOUT"`  // given to every query run by QueryRows (until it returns), QueryRow & Exec | 0 disables it
+	Postgres_query_timeouts map[string]time.Duration `env:"POSTGRES_QUERY_TIMEOUTS"` // keyed by the query file name ex: export_readings
 }
 
 type mongo struct {
-	Mongo_host     string
-	Mongo_port     string
-	Mongo_db       string
-	Mongo_user     string
-	Mongo_password string
-	Mongo_ssl      string
+	Mongo_enabled  bool   `env:"MONGODB_ENABLED"`
+	Mongo_host     string `env:"MONGODB_HOST"`
+	Mongo_port     int    `env:"MONGODB_PORT"`
+	Mongo_db       string `env:"MONGODB_DATABASE"`
+	Mongo_user     string `env:"MONGODB_USER"`
+	Mongo_password string `env:"MONGODB_PASSWORD" secret:"true"`
+	Mongo_ssl      bool   `env:"MONGODB_SSL"`
 }
 
 type redis struct {
-	Redis_host     string
-	Redis_port     string
-	Redis_password string
+	Redis_enabled  bool   `env:"REDIS_ENABLED"`
+	Redis_host     string `env:"REDIS_HOST"`
+	Redis_port     int    `env:"REDIS_PORT"`
+	Redis_password string `env:"REDIS_PASSWORD,Redis_PASSWORD" secret:"true"` // Redis_PASSWORD is the name older .env files use
 }
 
-func loadConfig() (*_PhoeniciaDigitalConfig, error) {
-	// Load environment variables from the .env file
-	err := godotenv.Load("./config/.env")
+// The GPIO range map of a raspberry pi zero w v1
+const (
+	minGPIOPin int = 2
+	maxGPIOPin int = 27
+)
+
+// Load & validate the config | also returns the source of every value not left to its default keyed by its env name
+func loadConfig() (*_PhoeniciaDigitalConfig, map[string]string, error) {
+	// Read the .env file if there is one | it is only read (never exported into the environment) so a reload
+	// sees the new file values while real environment variables keep taking precedence over them
+	envValues, err := godotenv.Read(envFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, err
+		}
+		log.Printf("No %s file found | Using the environment variables & defaults", envFile)
+	}
+
+	flags := parseFlags(os.Args[1:])
+	fileValues, err := readConfigFile(configFilePath(flags))
 	if err != nil {
-		log.Fatalf("Error loading .env file: %s", err)
+		return nil, nil, err
 	}
 
-	// Create a new _BEUConfig struct and populate it with values from environment variables
+	// Precedence: flags > env (the real environment then ./config/.env) > config file > defaults
+	l := &loader{asked: map[string]bool{}, sources: map[string]string{}, layers: []layer{
+		{source: "flag", lookup: func(name string) (string, bool) {
+			for flag, env := range configFlags {
+				if env == name {
+					value, ok := flags[flag]
+					return value, ok
+				}
+			}
+			return "", false
+		}},
+		{source: "env", lookup: func(name string) (string, bool) {
+			if value, ok := os.LookupEnv(name); ok {
+				return value, true
+			}
+			value, ok := envValues[name]
+			return value, ok
+		}},
+		{source: "file", lookup: func(name string) (string, bool) {
+			value, ok := fileValues[normalizeKey(name)]
+			return value.value, ok
+		}},
+	}}
+
+	// Create a new _PhoeniciaDigitalConfig struct and populate it with typed values from environment variables
+	projectName := l.str("PROJECT_NAME", "Phoenicia-Digital")
 	config := &_PhoeniciaDigitalConfig{
-		Project_Name: os.Getenv("PROJECT_NAME"),
-		Port:         fmt.Sprintf(":%s", os.Getenv("PORT")),
+		Project_Name: projectName,
+		Port:         l.integer("PORT", 8080, 0, 65535),
 		Postgres: postgres{
-			Postgres_host:     os.Getenv("POSTGRES_HOST"),
-			Postgres_port:     os.Getenv("POSTGRES_PORT"),
-			Postgres_user:     os.Getenv("POSTGRES_USER"),
-			Postgres_password: os.Getenv("POSTGRES_PASSWORD"),
-			Postgres_db:       os.Getenv("POSTGRES_DB"),
-			Postgres_ssl:      os.Getenv("POSTGRES_SSL"),
+			Postgres_enabled: l.boolean("POSTGRES_ENABLED", false),
+			// Due to how our containers are set up the host defaults to the {Project Name}-Postgres service
+			Postgres_host:     l.str("POSTGRES_HOST", projectName+"-Postgres"),
+			Postgres_port:     l.integer("POSTGRES_PORT", 5432, 0, 65535),
+			Postgres_user:     l.str("POSTGRES_USER", ""),
+			Postgres_password: l.str("POSTGRES_PASSWORD", ""),
+			Postgres_db:       l.str("POSTGRES_DB", ""),
+			Postgres_ssl:      l.oneOf("POSTGRES_SSL", "disable", "disable", "require", "verify-ca", "verify-full"),
+
+			Postgres_sql_dir:          l.str("POSTGRES_SQL_DIR", ""),
+			Postgres_prepare_on_start: l.boolean("POSTGRES_PREPARE_ON_START", false),
+			Postgres_sql_reload:       l.boolean("POSTGRES_SQL_RELOAD", false),
+
+			Postgres_query_timeout:  l.duration("POSTGRES_QUERY_TIMEOUT", 10*time.Second),
+			Postgres_query_timeouts: l.durations("POSTGRES_QUERY_TIMEOUTS"),
 		},
 		Mongo: mongo{
-			Mongo_host:     os.Getenv("MONGODB_HOST"),
-			Mongo_port:     os.Getenv("MONGODB_PORT"),
-			Mongo_db:       os.Getenv("MONGODB_DATABASE"),
-			Mongo_user:     os.Getenv("MONGODB_USER"),
-			Mongo_password: os.Getenv("MONGODB_PASSWORD"),
-			Mongo_ssl:      os.Getenv("MONGODB_SSL"),
+			Mongo_enabled:  l.boolean("MONGODB_ENABLED", false),
+			Mongo_host:     l.str("MONGODB_HOST", projectName+"-Mongodb"),
+			Mongo_port:     l.integer("MONGODB_PORT", 27017, 0, 65535),
+			Mongo_db:       l.str("MONGODB_DATABASE", ""),
+			Mongo_user:     l.str("MONGODB_USER", ""),
+			Mongo_password: l.str("MONGODB_PASSWORD", ""),
+			Mongo_ssl:      l.boolean("MONGODB_SSL", false),
 		},
 		Redis: redis{
-			Redis_host:     os.Getenv("REDIS_HOST"),
-			Redis_port:     os.Getenv("REDIS_PORT"),
-			Redis_password: os.Getenv("Redis_PASSWORD"),
+			Redis_enabled:  l.boolean("REDIS_ENABLED", false),
+			Redis_host:     l.str("REDIS_HOST", "localhost"),
+			Redis_port:     l.integer("REDIS_PORT", 6379, 0, 65535),
+			Redis_password: l.str("REDIS_PASSWORD", l.str("Redis_PASSWORD", "")),
+		},
+		Database: database{
+			Db_connect_initial_backoff: l.duration("DB_CONNECT_INITIAL_BACKOFF", 500*time.Millisecond),
+			Db_connect_max_backoff:     l.duration("DB_CONNECT_MAX_BACKOFF", 15*time.Second),
+			Db_connect_max_wait:        l.duration("DB_CONNECT_MAX_WAIT", 2*time.Minute),
+			Db_monitor_interval:        l.duration("DB_MONITOR_INTERVAL", 10*time.Second),
 		},
 		Pins: itepins{
-			TriggerPin:   os.Getenv("TriggerPin"),
-			EchoPin:      os.Getenv("EchoPin"),
-			MotorPin:     os.Getenv("MotorPin"),
-			RotateDegree: os.Getenv("RotateDegree"),
-			LoiterSpeed:  os.Getenv("LoiterSpeed"),
+			TriggerPin:   l.integer("TriggerPin", 14, minGPIOPin, maxGPIOPin),
+			EchoPin:      l.integer("EchoPin", 15, minGPIOPin, maxGPIOPin),
+			MotorPin:     l.integer("MotorPin", 23, minGPIOPin, maxGPIOPin),
+			RotateDegree: l.integer("RotateDegree", 5, 1, 180),
+			LoiterSpeed:  l.float("LoiterSpeed", 0.25, 0, 1),
+			MoveSpeed:    l.float("MoveSpeed", 0.15, 0, 1),
+		},
+		Calibration: calibration{
+			Servo_trim:   l.float("SERVO_TRIM", 0, -45, 45),
+			Sensor_scale: l.float("SENSOR_SCALE", 1, 0.000001, 1000),
+		},
+		Logging: logging{
+			Log_level:           l.oneOf("LOG_LEVEL", "info", "debug", "info", "warn", "warning", "error"),
+			Log_format:          l.oneOf("LOG_FORMAT", "text", "text", "json"),
+			Log_output:          l.oneOf("LOG_OUTPUT", "stderr", "stderr", "file", "both"),
+			Log_file:            l.str("LOG_FILE", "./Phoenicia-Digital.log"),
+			Log_max_size:        l.integer("LOG_MAX_SIZE", 10, 0, math.MaxInt32),
+			Log_max_age:         l.duration("LOG_MAX_AGE", 24*time.Hour),
+			Log_max_backups:     l.integer("LOG_MAX_BACKUPS", 7, 0, math.MaxInt32),
+			Log_max_archive_age: l.duration("LOG_MAX_ARCHIVE_AGE", 7*24*time.Hour),
+			Log_compress:        l.boolean("LOG_COMPRESS", true),
+		},
+		Cors: cors{
+			Cors_origins:     l.list("CORS_ORIGINS", []string{"http://localhost:3001"}),
+			Cors_methods:     l.list("CORS_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+			Cors_headers:     l.list("CORS_HEADERS", []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"}),
+			Cors_credentials: l.boolean("CORS_CREDENTIALS", false),
+			Cors_max_age:     l.integer("CORS_MAX_AGE", 600, 0, math.MaxInt32),
+		},
+		Auth: auth{
+			Auth_enabled:              l.boolean("AUTH_ENABLED", false),
+			Auth_api_keys:             l.list("AUTH_API_KEYS", []string{}),
+			Auth_api_keys_postgres:    l.boolean("AUTH_API_KEYS_POSTGRES", false),
+			Auth_jwt_hs256_secret:     l.str("AUTH_JWT_HS256_SECRET", ""),
+			Auth_jwt_rs256_public_key: l.str("AUTH_JWT_RS256_PUBLIC_KEY", ""),
+			Auth_jwt_issuer:           l.str("AUTH_JWT_ISSUER", ""),
+			Auth_jwt_audience:         l.str("AUTH_JWT_AUDIENCE", ""),
+		},
+		RateLimit: rateLimit{
+			Rate_limit_enabled: l.boolean("RATE_LIMIT_ENABLED", true),
+			Rate_limit_default: l.rateLimit("RATE_LIMIT_DEFAULT", RateLimit{Rate: 10, Burst: 20}),
+			Rate_limit_routes:  l.routeLimits("RATE_LIMIT_ROUTES"),
+			Rate_limit_motion:  l.rateLimit("RATE_LIMIT_MOTION", RateLimit{Rate: 2, Burst: 3}),
+		},
+		TLS: tls{
+			Tls_enabled:        l.boolean("TLS_ENABLED", false),
+			Tls_cert_file:      l.str("TLS_CERT_FILE", "./config/certs/server.crt"),
+			Tls_key_file:       l.str("TLS_KEY_FILE", "./config/certs/server.key"),
+			Tls_client_ca_file: l.str("TLS_CLIENT_CA_FILE", ""),
+			Tls_redirect_port:  l.integer("TLS_REDIRECT_PORT", 0, 0, 65535),
+			Tls_self_signed:    l.boolean("TLS_SELF_SIGNED", false),
+		},
+		Health: health{
+			Health_sensor_max_age: l.duration("HEALTH_SENSOR_MAX_AGE", 30*time.Second),
+		},
+		Alerts: alerts{
+			Alert_distance:      l.float("ALERT_DISTANCE", 0, 0, 400),
+			Alert_sensor_errors: l.integer("ALERT_SENSOR_ERRORS", 5, 0, math.MaxInt32),
 		},
 	}
 
-	return config, nil
+	// Keys of the config file that match no value are most likely typos
+	unknown := []string{}
+	for key, value := range fileValues {
+		if !l.asked[key] {
+			unknown = append(unknown, value.path)
+		}
+	}
+	sort.Strings(unknown)
+	for _, path := range unknown {
+		l.errs = append(l.errs, fmt.Errorf("%s: unknown config file key", path))
+	}
+
+	// A single validation pass | every invalid value is reported together instead of one per restart
+	return config, l.sources, errors.Join(append(l.errs, config.validate()...)...)
 }
 
-var Config *_PhoeniciaDigitalConfig
+// The config the API runs with | a reload publishes a new copy instead of changing this one so a config returned
+// by Config is never modified & can be read without any lock
+// Keep the returned pointer (config := PhoeniciaDigitalConfig.Config()) when several values must come from the same reload
+var current atomic.Pointer[_PhoeniciaDigitalConfig]
+
+func Config() *_PhoeniciaDigitalConfig {
+	return current.Load()
+}
 
 func init() {
-	config, err := loadConfig()
+	config, loadedSources, err := loadConfig()
 	if err != nil {
-		log.Fatalf("Error loading config: %s", err)
+		log.Fatalf("Error loading config | Change in the flags, the environment, %s or the config file:\n  %s", envFile, strings.ReplaceAll(err.Error(), "\n", "\n  "))
 	}
-	Config = config
+	current.Store(config)
+	sources = loadedSources
 }
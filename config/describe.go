@@ -0,0 +1,88 @@
+// File: `Config Description File` config/describe.go
+package PhoeniciaDigitalConfig
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Sources of the loaded values keyed by env name | a value missing from it was left to its default
+var sources map[string]string
+
+// Shown instead of the value of the fields tagged `secret`
+const redacted string = "[redacted]"
+
+// A single value of the effective config as shown by Describe
+type Value struct {
+	Env    string `json:"env"`
+	Value  any    `json:"value"`
+	Source string `json:"source"` // default | file | env | flag
+}
+
+// The env names a field is read from | the first one is its current name the others are legacy names
+func envNames(field reflect.StructField) []string {
+	return strings.Split(field.Tag.Get("env"), ",")
+}
+
+// Describe the effective config | every value with the env name it is read from & the layer it came from
+// Sections are nested by their field name ex: Postgres.Postgres_host & secrets are redacted
+func Describe() map[string]any {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	description := map[string]any{}
+	config := reflect.ValueOf(Config()).Elem()
+	for i := 0; i < config.NumField(); i++ {
+		field := config.Type().Field(i)
+
+		if field.Type.Kind() != reflect.Struct {
+			description[field.Name] = describeValue(field, config.Field(i))
+			continue
+		}
+
+		section := map[string]Value{}
+		for j := 0; j < field.Type.NumField(); j++ {
+			section[field.Type.Field(j).Name] = describeValue(field.Type.Field(j), config.Field(i).Field(j))
+		}
+		description[field.Name] = section
+	}
+	return description
+}
+
+func describeValue(field reflect.StructField, value reflect.Value) Value {
+	names := envNames(field)
+	described := Value{Env: names[0], Value: value.Interface(), Source: "default"}
+	for _, env := range names {
+		if source, ok := sources[env]; ok {
+			described.Source = source
+			break
+		}
+	}
+
+	// Shown the way they are written in the flags, the environment & the config file
+	switch typed := value.Interface().(type) {
+	case time.Duration:
+		described.Value = typed.String()
+	case RateLimit:
+		described.Value = typed.String()
+	case map[string]RateLimit:
+		routes := map[string]string{}
+		for route, limit := range typed {
+			routes[route] = limit.String()
+		}
+		described.Value = routes
+	case map[string]time.Duration:
+		durations := map[string]string{}
+		for name, duration := range typed {
+			durations[name] = duration.String()
+		}
+		described.Value = durations
+	}
+
+	// An empty secret is shown as is so a missing password can still be spotted
+	if field.Tag.Get("secret") == "true" && !value.IsZero() && !(value.Kind() == reflect.Slice && value.Len() == 0) {
+		described.Value = redacted
+	}
+	return described
+}
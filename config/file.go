@@ -0,0 +1,107 @@
+// File: `Config File Loading File` config/file.go
+package PhoeniciaDigitalConfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The structured config file read when --config / CONFIG_FILE are not given | optional
+const defaultConfigFile string = "./config/config.yaml"
+
+// Keys of the config file & names of the env variables are matched case insensitively ignoring _, - & the dots
+// between sections
+// so `postgres: {host: x}` sets POSTGRES_HOST & `rotate_degree: 5` sets RotateDegree
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToUpper(key))
+}
+
+// The config file given with --config or CONFIG_FILE | falls back to ./config/config.yaml if it exists
+// An explicitly given file that does not exist is an error while a missing default file is not
+func configFilePath(flags map[string]string) (string, bool) {
+	if path, ok := flags["config"]; ok {
+		return path, true
+	}
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok && path != "" {
+		return path, true
+	}
+	return defaultConfigFile, false
+}
+
+// A value of the config file & the dotted path it was written at (used in error messages)
+type fileValue struct {
+	path  string
+	value string
+}
+
+// Read the YAML config file into flat values keyed by their normalized env name
+// Sections nest with their env prefix ex: postgres.host -> POSTGRES_HOST | lists are joined with commas
+// Lists of mappings are refused since every setting is a single env value | their items are written in the env format
+func readConfigFile(path string, explicit bool) (map[string]fileValue, error) {
+	values := map[string]fileValue{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return values, nil
+		}
+		return nil, err
+	}
+
+	document := map[string]any{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := flatten("", document, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, value any, values map[string]fileValue) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			if err := flatten(path, v[key], values); err != nil {
+				return err
+			}
+		}
+	case []any:
+		items := []string{}
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return fmt.Errorf("%s: lists can only hold plain values written like the env ones | lists of mappings are not supported", prefix)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		values[normalizeKey(prefix)] = fileValue{path: prefix, value: strings.Join(items, ",")}
+	case nil:
+		// An empty value is the same as a value that is not set
+	default:
+		values[normalizeKey(prefix)] = fileValue{path: prefix, value: fmt.Sprint(v)}
+	}
+	return nil
+}
+
+// Files returns the config files a running API reads | watched so changes to them can be reloaded
+func Files() []string {
+	path, _ := configFilePath(parseFlags(os.Args[1:]))
+	return []string{envFile, path}
+}
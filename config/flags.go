@@ -0,0 +1,53 @@
+// File: `Config Flags File` config/flags.go
+package PhoeniciaDigitalConfig
+
+import (
+	"flag"
+	"strings"
+)
+
+// The command line flags that override every other config source | name -> env name it overrides
+// `config` is not a value itself but the path of the config file
+var configFlags = map[string]string{
+	"port":      "PORT",
+	"log-level": "LOG_LEVEL",
+	"config":    "",
+}
+
+// RegisterFlags declares the config flags on a flag.FlagSet so a command parsing its own flags accepts them
+// They are already applied since the config is loaded before main runs
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.String("port", "", "port the API listens on | overrides PORT")
+	fs.String("log-level", "", "debug | info | warn | error | overrides LOG_LEVEL")
+	fs.String("config", "", "path of the YAML config file | overrides CONFIG_FILE (default "+defaultConfigFile+")")
+}
+
+// Pick the config flags out of the command line wherever they are (before or after a subcommand)
+// Accepts -name value, --name value, -name=value & --name=value | every other argument is left alone
+func parseFlags(args []string) map[string]string {
+	flags := map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if _, known := configFlags[name]; !known {
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				continue
+			}
+			i++
+			value = args[i]
+		}
+		flags[name] = value
+	}
+	return flags
+}
@@ -0,0 +1,319 @@
+// File: `Config Loading File` config/load.go
+package PhoeniciaDigitalConfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A source of config values | named flag, env or file
+type layer struct {
+	source string
+	lookup func(name string) (string, bool)
+}
+
+// loader reads every config value from its layers converting it to its type & falling back to its default
+// when no layer holds it | The layers are asked in order so the first one holding a value wins
+// Every invalid value is collected so a single run reports all of them at once
+type loader struct {
+	layers  []layer
+	errs    []error
+	asked   map[string]bool   // normalized names of every value read | used to find unknown config file keys
+	sources map[string]string // the layer each value was read from | values missing from it are defaults
+}
+
+func (l *loader) fail(name string, format string, args ...any) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+}
+
+// The trimmed value of name from the first layer holding it | false when no layer holds a non empty value
+func (l *loader) value(name string) (string, bool) {
+	l.asked[normalizeKey(name)] = true
+	for _, layer := range l.layers {
+		if value, ok := layer.lookup(name); ok && strings.TrimSpace(value) != "" {
+			l.sources[name] = layer.source
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+func (l *loader) str(name string, def string) string {
+	if value, ok := l.value(name); ok {
+		return value
+	}
+	return def
+}
+
+// A string that must be one of the allowed values (compared case insensitively)
+func (l *loader) oneOf(name string, def string, allowed ...string) string {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	for _, option := range allowed {
+		if strings.EqualFold(value, option) {
+			return option
+		}
+	}
+	l.fail(name, "must be one of %s got: %s", strings.Join(allowed, ", "), value)
+	return def
+}
+
+func (l *loader) integer(name string, def int, min int, max int) int {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		l.fail(name, "must be a whole number got: %s", value)
+		return def
+	}
+	if number < min || number > max {
+		l.fail(name, "must be in range %d --> %d got: %d", min, max, number)
+		return def
+	}
+	return number
+}
+
+func (l *loader) float(name string, def float64, min float64, max float64) float64 {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.fail(name, "must be a number got: %s", value)
+		return def
+	}
+	if number < min || number > max {
+		l.fail(name, "must be in range %g --> %g got: %g", min, max, number)
+		return def
+	}
+	return number
+}
+
+func (l *loader) boolean(name string, def bool) bool {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		l.fail(name, "must be true or false got: %s", value)
+		return def
+	}
+	return result
+}
+
+func (l *loader) duration(name string, def time.Duration) time.Duration {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil || result < 0 {
+		l.fail(name, "must be a duration >= 0 ex: 24h got: %s", value)
+		return def
+	}
+	return result
+}
+
+// A comma separated list | spaces around the entries & empty entries are dropped
+func (l *loader) list(name string, def []string) []string {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	return splitList(value)
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// A RateLimit is a rate in tokens per second & the size of the bursts allowed on top of it
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Parse a rate limit written as <rate>:<burst> ex: 2:4 allows 2 calls a second with bursts of 4
+func ParseRateLimit(value string) (RateLimit, error) {
+	rate, burst, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		return RateLimit{}, fmt.Errorf("rate limit must be <rate>:<burst> got: %s", value)
+	}
+
+	limit := RateLimit{}
+	var err error
+	if limit.Rate, err = strconv.ParseFloat(strings.TrimSpace(rate), 64); err != nil || limit.Rate <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit rate must be a number > 0 got: %s", rate)
+	}
+	if limit.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || limit.Burst < 1 {
+		return RateLimit{}, fmt.Errorf("rate limit burst must be a whole number >= 1 got: %s", burst)
+	}
+	return limit, nil
+}
+
+// A RateLimit written as <rate>:<burst> | the format ParseRateLimit reads
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%g:%d", l.Rate, l.Burst)
+}
+
+func (l *loader) rateLimit(name string, def RateLimit) RateLimit {
+	value, ok := l.value(name)
+	if !ok {
+		return def
+	}
+	limit, err := ParseRateLimit(value)
+	if err != nil {
+		l.fail(name, "%s", err)
+		return def
+	}
+	return limit
+}
+
+// Comma separated <route pattern>=<rate>:<burst> entries
+func (l *loader) routeLimits(name string) map[string]RateLimit {
+	limits := map[string]RateLimit{}
+	value, ok := l.value(name)
+	if !ok {
+		return limits
+	}
+
+	for _, entry := range splitList(value) {
+		pattern, limitValue, found := strings.Cut(entry, "=")
+		if !found {
+			l.fail(name, "entry must be <route>=<rate>:<burst> got: %s", entry)
+			continue
+		}
+
+		limit, err := ParseRateLimit(limitValue)
+		if err != nil {
+			l.fail(name, "%s: %s", strings.TrimSpace(pattern), err)
+			continue
+		}
+		limits[strings.TrimSpace(pattern)] = limit
+	}
+	return limits
+}
+
+// Comma separated <name>=<duration> entries ex: export_readings=5m
+func (l *loader) durations(name string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	value, ok := l.value(name)
+	if !ok {
+		return durations
+	}
+
+	for _, entry := range splitList(value) {
+		key, durationValue, found := strings.Cut(entry, "=")
+		if !found {
+			l.fail(name, "entry must be <name>=<duration> got: %s", entry)
+			continue
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(durationValue))
+		if err != nil || duration < 0 {
+			l.fail(name, "%s: must be a duration >= 0 ex: 30s got: %s", strings.TrimSpace(key), durationValue)
+			continue
+		}
+		durations[strings.TrimSpace(key)] = duration
+	}
+	return durations
+}
+
+// Cross field checks that can only run once every value is read
+func (c *_PhoeniciaDigitalConfig) validate() []error {
+	errs := []error{}
+
+	pins := map[string]int{"TriggerPin": c.Pins.TriggerPin, "EchoPin": c.Pins.EchoPin, "MotorPin": c.Pins.MotorPin}
+	used := map[int]string{}
+	for _, name := range []string{"TriggerPin", "EchoPin", "MotorPin"} {
+		if other, taken := used[pins[name]]; taken {
+			errs = append(errs, fmt.Errorf("%s: CANT be assigned to the same pin as %s got: %d", name, other, pins[name]))
+		}
+		used[pins[name]] = name
+	}
+
+	for _, origin := range c.Cors.Cors_origins {
+		if _, err := path.Match(origin, ""); err != nil {
+			errs = append(errs, fmt.Errorf("CORS_ORIGINS: invalid pattern: %s", origin))
+		}
+	}
+
+	// Reflecting any origin with credentials would give every website credentialed access
+	if c.Cors.Cors_credentials && slices.Contains(c.Cors.Cors_origins, "*") {
+		errs = append(errs, errors.New("CORS_CREDENTIALS: must be false when CORS_ORIGINS allows any origin (*)"))
+	}
+
+	// The databases are opt-in | an enabled database needs the values it can not connect without
+	if c.Postgres.Postgres_enabled && (c.Postgres.Postgres_user == "" || c.Postgres.Postgres_db == "") {
+		errs = append(errs, errors.New("POSTGRES_ENABLED: true but POSTGRES_USER or POSTGRES_DB is empty"))
+	}
+
+	if c.Mongo.Mongo_enabled && c.Mongo.Mongo_db == "" {
+		errs = append(errs, errors.New("MONGODB_ENABLED: true but MONGODB_DATABASE is empty"))
+	}
+
+	if c.Database.Db_connect_initial_backoff <= 0 || c.Database.Db_connect_initial_backoff > c.Database.Db_connect_max_backoff {
+		errs = append(errs, fmt.Errorf("DB_CONNECT_INITIAL_BACKOFF: must be > 0 & <= DB_CONNECT_MAX_BACKOFF (%s) got: %s", c.Database.Db_connect_max_backoff, c.Database.Db_connect_initial_backoff))
+	}
+
+	if c.Database.Db_monitor_interval < time.Second {
+		errs = append(errs, fmt.Errorf("DB_MONITOR_INTERVAL: must be at least 1s got: %s", c.Database.Db_monitor_interval))
+	}
+
+	if c.Auth.Auth_api_keys_postgres && !c.Postgres.Postgres_enabled {
+		errs = append(errs, errors.New("AUTH_API_KEYS_POSTGRES: true but POSTGRES_ENABLED is false"))
+	}
+
+	if dir := c.Postgres.Postgres_sql_dir; dir != "" {
+		if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+			errs = append(errs, fmt.Errorf("POSTGRES_SQL_DIR: must be an existing folder got: %s", dir))
+		}
+	}
+
+	// The query files built into the binary never change
+	if c.Postgres.Postgres_sql_reload && c.Postgres.Postgres_sql_dir == "" {
+		errs = append(errs, errors.New("POSTGRES_SQL_RELOAD: true but POSTGRES_SQL_DIR is empty"))
+	}
+
+	if c.Postgres.Postgres_prepare_on_start && !c.Postgres.Postgres_enabled {
+		errs = append(errs, errors.New("POSTGRES_PREPARE_ON_START: true but POSTGRES_ENABLED is false"))
+	}
+
+	if c.Auth.Auth_jwt_hs256_secret != "" && len(c.Auth.Auth_jwt_hs256_secret) < 32 {
+		errs = append(errs, errors.New("AUTH_JWT_HS256_SECRET: must be at least 32 characters long"))
+	}
+
+	if c.Auth.Auth_enabled && len(c.Auth.Auth_api_keys) == 0 && !c.Auth.Auth_api_keys_postgres &&
+		c.Auth.Auth_jwt_hs256_secret == "" && c.Auth.Auth_jwt_rs256_public_key == "" {
+		errs = append(errs, errors.New("AUTH_ENABLED: true but no API keys or JWT keys are configured"))
+	}
+
+	if c.TLS.Tls_redirect_port != 0 && c.TLS.Tls_redirect_port == c.Port {
+		errs = append(errs, fmt.Errorf("TLS_REDIRECT_PORT: must differ from PORT got: %d", c.TLS.Tls_redirect_port))
+	}
+
+	// Client certificates only exist over TLS | accepting the CA without it would leave the admin routes open
+	if c.TLS.Tls_client_ca_file != "" && !c.TLS.Tls_enabled {
+		errs = append(errs, errors.New("TLS_CLIENT_CA_FILE: set but TLS_ENABLED is false | client certificates need TLS"))
+	}
+
+	return errs
+}
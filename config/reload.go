@@ -0,0 +1,86 @@
+// File: `Config Reload File` config/reload.go
+package PhoeniciaDigitalConfig
+
+import (
+	"reflect"
+	"sync"
+)
+
+// The values that can change while the API runs | every other change needs a restart (pins, ports, databases, ...)
+var reloadable = map[string]bool{
+	"Pins.RotateDegree":            true,
+	"Pins.LoiterSpeed":             true,
+	"Pins.MoveSpeed":               true,
+	"RateLimit.Rate_limit_motion":  true,
+	"Cors.Cors_origins":            true,
+	"Cors.Cors_methods":            true,
+	"Cors.Cors_headers":            true,
+	"Cors.Cors_credentials":        true,
+	"Cors.Cors_max_age":            true,
+	"Logging.Log_level":            true,
+	"Health.Health_sensor_max_age": true,
+	"Alerts.Alert_distance":        true,
+	"Alerts.Alert_sensor_errors":   true,
+
+	"Postgres.Postgres_query_timeout":  true,
+	"Postgres.Postgres_query_timeouts": true,
+}
+
+// Held while a reload builds the next config & updates the sources | Describe holds it to read the sources
+var mu sync.RWMutex
+
+// Reload reads & validates the config again publishing a copy of the current config holding the reloadable changes
+// Returns the values that were applied & the ones that were rejected because they need a restart
+// An invalid config is rejected as a whole & nothing is applied
+func Reload() (applied []string, rejected []string, err error) {
+	next, nextSources, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The config in use is never written | readers holding it keep a consistent view until they call Config again
+	updated := *Config()
+	config := reflect.ValueOf(&updated).Elem()
+	loaded := reflect.ValueOf(next).Elem()
+	for i := 0; i < config.NumField(); i++ {
+		section := config.Type().Field(i).Name
+
+		if config.Field(i).Kind() != reflect.Struct {
+			if !reflect.DeepEqual(config.Field(i).Interface(), loaded.Field(i).Interface()) {
+				rejected = append(rejected, section)
+			}
+			continue
+		}
+
+		for j := 0; j < config.Field(i).NumField(); j++ {
+			field := config.Field(i).Type().Field(j)
+			name := section + "." + field.Name
+			from, to := config.Field(i).Field(j), loaded.Field(i).Field(j)
+			if reflect.DeepEqual(from.Interface(), to.Interface()) {
+				continue
+			}
+
+			if reloadable[name] {
+				from.Set(to)
+				for _, env := range envNames(field) {
+					if source, ok := nextSources[env]; ok {
+						sources[env] = source
+					} else {
+						delete(sources, env)
+					}
+				}
+				applied = append(applied, name)
+			} else {
+				rejected = append(rejected, name)
+			}
+		}
+	}
+
+	if len(applied) > 0 {
+		current.Store(&updated)
+	}
+	return applied, rejected, nil
+}
@@ -22,7 +22,9 @@ require (
 
 require github.com/redis/go-redis/v9 v9.7.0
 
-require github.com/gorilla/websocket v1.5.3 // indirect
+require github.com/gorilla/websocket v1.5.3
+
+require gopkg.in/yaml.v3 v3.0.1 // direct
 
 require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
@@ -1,16 +1,13 @@
 package main
 
 import (
-	PhoeniciaDigitalServer "Phoenicia-Digital-Base-API/base/server"
+	PhoeniciaDigitalCLI "Phoenicia-Digital-Base-API/base/cli"
+	"os"
 )
 
+// The databases are enabled in ./config/.env (POSTGRES_ENABLED, MONGODB_ENABLED & REDIS_ENABLED), connect on first use
+// & are closed once the command returns
 func main() {
-
-	//	if Postgres Database Not In use comment out
-	// defer PhoeniciaDigitalDatabase.Postgres.DB.Close()
-
-	// if MongoDB Database Not In Use comment out
-	// defer PhoeniciaDigitalDatabase.Mongo.Client.Disconnect(context.Background())
-
-	PhoeniciaDigitalServer.StartServer()
+	// serve (the default), check-config, calibrate, scan, measure & migrate | ./main help lists them
+	os.Exit(PhoeniciaDigitalCLI.Run(os.Args[1:]))
 }
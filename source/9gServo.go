@@ -4,10 +4,11 @@ import (
 	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
 	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
-	"strconv"
+	"sync"
 
 	"github.com/cgxeiji/servo"
 )
@@ -16,10 +17,19 @@ type servoMotor struct {
 	Motor        *servo.Servo
 	loitering    bool
 	loiterSpeed  float32
+	moveSpeed    float64 // speed of rotations, scans & calibration moves
 	currentPos   float64
 	rotateDegree int
+	trim         atomicFloat // SERVO_TRIM found with `calibrate servo` | can change with PUT /calibration
+	connected    bool
 	ctx          context.Context
 	cancel       context.CancelFunc
+
+	// Guards currentPos & moving | currentPos is the position last commanded while moving reports if a
+	// goroutine is driving the servo towards it so new commands only update the target (coalescing bursts)
+	mu     sync.Mutex
+	moving bool
+	motion *PhoeniciaDigitalUtils.TokenBucket // shared limit on how often the servo starts a new move
 }
 
 type servoResponse struct {
@@ -29,142 +39,234 @@ type servoResponse struct {
 
 var ServoMotor *servoMotor = &servoMotor{}
 
+// Answer a failed servo command | exceeding the motion limit is a 429 with Retry-After
+func servoError(w http.ResponseWriter, err error, code int) PhoeniciaDigitalUtils.ApiError {
+	var limited *PhoeniciaDigitalUtils.RateLimitError
+	if errors.As(err, &limited) {
+		return PhoeniciaDigitalUtils.TooManyRequests(w, limited)
+	}
+	return PhoeniciaDigitalUtils.ApiError{Code: code, Quote: err.Error()}
+}
+
 func HandleLoiter(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
 	if err := ServoMotor.Loiter(); err != nil {
-		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: "Failed to Toggle Loiter"}
+		return servoError(w, err, http.StatusConflict)
 	}
 
-	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: "Loiter Toggled", Degree: int(ServoMotor.currentPos)}}
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: "Loiter Toggled", Degree: int(ServoMotor.Position())}}
 }
 
 func HandleRotateRight(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
 	if err := ServoMotor.RotateRight(); err != nil {
-		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusConflict, Quote: err.Error()}
+		return servoError(w, err, http.StatusConflict)
 	}
 
-	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: fmt.Sprintf("Rotated %d Degrees to the Right", ServoMotor.rotateDegree), Degree: int(ServoMotor.currentPos)}}
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: fmt.Sprintf("Rotated %d Degrees to the Right", ServoMotor.RotateDegree()), Degree: int(ServoMotor.Position())}}
 }
 
 func HandleRotateLeft(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
 	if err := ServoMotor.RotateLeft(); err != nil {
-		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusConflict, Quote: err.Error()}
+		return servoError(w, err, http.StatusConflict)
 	}
 
-	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: fmt.Sprintf("Rotated %d Degrees to the Left", ServoMotor.rotateDegree), Degree: int(ServoMotor.currentPos)}}
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: servoResponse{Message: fmt.Sprintf("Rotated %d Degrees to the Left", ServoMotor.RotateDegree()), Degree: int(ServoMotor.Position())}}
 }
 
 func (s *servoMotor) InitializeServoMotor() {
 
-	// Check Pin Conversion from the .env file (should be actual numbers and in range of the raspberry pi zero w pins)
-	// If an issue occured with conversion the program wont run!
-	motorPin, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Pins.MotorPin)
-	if err != nil {
-		PhoeniciaDigitalUtils.Log("Motor Pin Value in .env file is an invalid pin number")
-		log.Fatalf("Motor Pin Value in .env file is an invalid pin number")
-	}
-
-	// Make sure the Motor pin is in the GPIO range map of a raspberry pi zero w v1
-	if motorPin < 2 || motorPin > 27 {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Motor pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", motorPin))
-		log.Fatalf("Motor pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", motorPin)
-	}
-
-	// Set Desired Rotation Degree
-	rotationdeg, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Pins.RotateDegree)
-	if err != nil {
-		PhoeniciaDigitalUtils.Log("Rotation Degrees invalid | Edit .env file to fix error")
-		log.Fatalf("Rotation Degrees invalid | Edit .env file to fix error")
-	}
-
-	loitspeed, err := strconv.ParseFloat(PhoeniciaDigitalConfig.Config.Pins.LoiterSpeed, 32)
-	if err != nil {
-		PhoeniciaDigitalUtils.Log("Failed to convert Loiter Speed to float32")
-		log.Fatalf("Failed to convert Loiter Speed to float32")
-	}
+	// The pins, rotation degree & loiter speed are typed & range checked (GPIO map [2 -> 27]) when the config is loaded
+	pins := PhoeniciaDigitalConfig.Config().Pins
+	motorPin := pins.MotorPin
+	motionLimit := PhoeniciaDigitalConfig.Config().RateLimit.Rate_limit_motion
 
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 
+	s.motion = PhoeniciaDigitalUtils.NewTokenBucket(motionLimit.Rate, motionLimit.Burst)
 	s.Motor = servo.New(motorPin)
 	s.loitering = false
-	s.loiterSpeed = float32(loitspeed)
+	s.loiterSpeed = float32(pins.LoiterSpeed)
+	s.moveSpeed = pins.MoveSpeed
 	s.currentPos = 90.0
-	s.rotateDegree = rotationdeg
+	s.rotateDegree = pins.RotateDegree
+	s.trim.Store(PhoeniciaDigitalConfig.Config().Calibration.Servo_trim)
 
 	if err := s.Motor.Connect(); err != nil {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to connect to Servo Motor | Error: %s", err.Error()))
-		log.Fatalf("Failed to connect to Servo Motor | Error: %s", err.Error())
+		PhoeniciaDigitalUtils.Fatal("Failed to connect to Servo Motor", "pin", motorPin, "error", err)
 	}
+	s.connected = true
 
-	PhoeniciaDigitalUtils.Log(fmt.Sprintf("Initialized Servo with Pin: %d, Loiter Speed: %f, & Rotation Degrees: %d", motorPin, s.loiterSpeed, s.rotateDegree))
-	log.Printf("Initialized Servo with Pin: %d, Loiter Speed: %f, & Rotation Degrees: %d", motorPin, s.loiterSpeed, s.rotateDegree)
+	PhoeniciaDigitalUtils.Logger.Info("Initialized Servo", "pin", motorPin, "loiter_speed", s.loiterSpeed, "move_speed", s.moveSpeed, "rotate_degree", s.rotateDegree, "trim", s.trim.Load(), "motion_rate", motionLimit.Rate, "motion_burst", motionLimit.Burst)
 
-	s.Motor.MoveTo(s.currentPos).Wait()
+	s.Motor.MoveTo(s.trimmed(s.currentPos)).Wait()
 
 }
 
+// The angle sent to the servo for a position | the trim is added & the result kept in the 0 --> 180 Degrees range
+func (s *servoMotor) trimmed(degree float64) float64 {
+	return clampPosition(degree + s.trim.Load())
+}
+
+// Keep a position in the 0 --> 180 Degrees range of the servo
+func clampPosition(degree float64) float64 {
+	return math.Max(0, math.Min(180, degree))
+}
+
+// Reconfigure applies the motion values of the config (RotateDegree, LoiterSpeed, MoveSpeed & RATE_LIMIT_MOTION)
+// Called after a config reload so the servo picks them up without a restart or re-homing
+func (s *servoMotor) Reconfigure() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config := PhoeniciaDigitalConfig.Config()
+	pins := config.Pins
+	motionLimit := config.RateLimit.Rate_limit_motion
+
+	s.rotateDegree = pins.RotateDegree
+	s.loiterSpeed = float32(pins.LoiterSpeed)
+	s.moveSpeed = pins.MoveSpeed
+	// The bucket is kept so a reload does not hand out a fresh burst of moves
+	s.motion.SetLimit(motionLimit.Rate, motionLimit.Burst)
+
+	PhoeniciaDigitalUtils.Logger.Info("Reconfigured Servo", "loiter_speed", s.loiterSpeed, "move_speed", s.moveSpeed, "rotate_degree", s.rotateDegree)
+}
+
+// Reports if the connection to the Servo Motor (pi-blaster) was established
+func (s *servoMotor) Connected() bool {
+	return s.connected
+}
+
+// The position last commanded to the servo
+func (s *servoMotor) Position() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.currentPos
+}
+
+// The Degrees a single rotation moves the servo | can change on a config reload
+func (s *servoMotor) RotateDegree() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotateDegree
+}
+
+// The speed of the loiter sweeps | can change on a config reload
+func (s *servoMotor) LoiterSpeed() float32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loiterSpeed
+}
+
+// Take a token from the motion limiter | the caller must hold s.mu
+func (s *servoMotor) takeMotion() error {
+	if allowed, retryAfter := s.motion.Take(); !allowed {
+		servoLimited.Inc()
+		return &PhoeniciaDigitalUtils.RateLimitError{Limit: "servo motion", RetryAfter: retryAfter}
+	}
+	return nil
+}
+
 func (s *servoMotor) Loiter() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.loitering {
+		if s.moving {
+			return fmt.Errorf("cannot loiter while the servo is moving")
+		}
+		if err := s.takeMotion(); err != nil {
+			return err
+		}
+
 		s.loitering = true
+		servoLoitering.Set(1)
 		s.ctx, s.cancel = context.WithCancel(context.Background())
 
-		go func() {
-			// defer s.wg.Done()
+		// The sweeper only watches the ctx it was started with | s.ctx is replaced by the next Loiter under s.mu
+		go func(ctx context.Context) {
 			for {
 				select {
-				case <-s.ctx.Done():
+				case <-ctx.Done():
 					return
 				default:
-					s.Motor.SetSpeed(0.15)
-					s.Motor.MoveTo(180).Wait()
-					s.Motor.MoveTo(0).Wait()
+					// Read every sweep so a reloaded LoiterSpeed applies without toggling loiter
+					s.Motor.SetSpeed(float64(s.LoiterSpeed()))
+					s.Motor.MoveTo(s.trimmed(180)).Wait()
+					observeServoMove("loiter", 0, 180)
+					s.Motor.MoveTo(s.trimmed(0)).Wait()
+					observeServoMove("loiter", 180, 0)
 				}
 			}
-		}()
+		}(s.ctx)
 	} else {
 		s.Motor.SetSpeed(0)
 
 		s.cancel()
-		s.Motor.SetPosition(s.currentPos)
+		s.Motor.SetPosition(s.trimmed(s.currentPos))
 
 		s.loitering = false
+		servoLoitering.Set(0)
+		SessionRecorder.CaptureServo(s.currentPos)
 	}
 
 	return nil
 }
 
 func (s *servoMotor) RotateRight() error {
-	if s.loitering {
-		return fmt.Errorf("cannot rotate while loitering")
-	}
-
-	if s.currentPos < 180 {
-		s.currentPos += float64(s.rotateDegree)
-		s.Motor.SetSpeed(0.15)
-		s.Motor.MoveTo(s.currentPos).Wait()
-
-	} else {
-		return fmt.Errorf("cannot rotate max angle reached %f Degrees", s.currentPos)
-	}
-
-	return nil
+	return s.rotate(1, "rotate-right")
 }
 
 func (s *servoMotor) RotateLeft() error {
+	return s.rotate(-1, "rotate-left")
+}
+
+// Move the commanded position by rotateDegree in the direction (1 right | -1 left) | while a move is running the new position is only
+// recorded & the running move carries on to it so a burst of commands becomes a single move
+// Starting a new move takes a token from the motion limiter returning a RateLimitError when there is none
+func (s *servoMotor) rotate(direction float64, origin string) error {
+	s.mu.Lock()
+	delta := direction * float64(s.rotateDegree)
 	if s.loitering {
+		s.mu.Unlock()
 		return fmt.Errorf("cannot rotate while loitering")
 	}
 
-	if s.currentPos > 0 {
-		s.currentPos -= float64(s.rotateDegree)
-		s.Motor.SetSpeed(0.15)
-		s.Motor.MoveTo(s.currentPos).Wait()
-	} else {
+	if (delta > 0 && s.currentPos >= 180) || (delta < 0 && s.currentPos <= 0) {
+		s.mu.Unlock()
 		return fmt.Errorf("cannot rotate max angle reached %f Degrees", s.currentPos)
 	}
 
-	return nil
-}
+	// A position within rotateDegree of a limit only moves up to it
+	if s.moving {
+		s.currentPos = clampPosition(s.currentPos + delta)
+		servoCoalesced.Inc()
+		s.mu.Unlock()
+		return nil
+	}
 
-func init() {
-	ServoMotor.InitializeServoMotor()
+	if err := s.takeMotion(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	from := s.currentPos
+	s.currentPos = clampPosition(s.currentPos + delta)
+	target, speed := s.currentPos, s.moveSpeed
+	s.moving = true
+	s.mu.Unlock()
+
+	for {
+		s.Motor.SetSpeed(speed)
+		s.Motor.MoveTo(s.trimmed(target)).Wait()
+		observeServoMove(origin, from, target)
+		SessionRecorder.CaptureServo(target)
+
+		s.mu.Lock()
+		if s.currentPos == target {
+			s.moving = false
+			s.mu.Unlock()
+			return nil
+		}
+		from, target, speed = target, s.currentPos, s.moveSpeed
+		s.mu.Unlock()
+	}
 }
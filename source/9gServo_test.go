@@ -0,0 +1,47 @@
+// File: `Servo Motor Tests File` source/9gServo_test.go
+package source
+
+import "testing"
+
+// Rotations while a move is running only update the target so they run without a connected servo
+func TestRotateStaysWithinTheServoRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		position  float64
+		direction float64
+		want      float64
+		wantErr   bool
+	}{
+		{name: "right within range", position: 90, direction: 1, want: 95},
+		{name: "left within range", position: 90, direction: -1, want: 85},
+		{name: "right near the limit stops at 180", position: 178, direction: 1, want: 180},
+		{name: "left near the limit stops at 0", position: 2, direction: -1, want: 0},
+		{name: "right at the limit is refused", position: 180, direction: 1, want: 180, wantErr: true},
+		{name: "left at the limit is refused", position: 0, direction: -1, want: 0, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &servoMotor{currentPos: test.position, rotateDegree: 5, moving: true}
+
+			err := s.rotate(test.direction, "test")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v want error %v", err, test.wantErr)
+			}
+			if got := s.Position(); got != test.want {
+				t.Errorf("position = %v want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRotateBurstStopsAtTheLimit(t *testing.T) {
+	s := &servoMotor{currentPos: 170, rotateDegree: 4, moving: true}
+
+	for i := 0; i < 5; i++ {
+		s.rotate(1, "test")
+	}
+	if got := s.Position(); got != 180 {
+		t.Errorf("position after a burst = %v want 180", got)
+	}
+}
@@ -0,0 +1,49 @@
+package source
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+)
+
+// Every sample of the sampler is checked against the ALERT_* thresholds | an alert is logged once when it is raised
+// & once when it clears instead of on every sample | The thresholds are read on every check so a reload applies
+// to the next sample
+
+type sensorAlerts struct {
+	close  bool // the last successful reading was closer than ALERT_DISTANCE
+	errors int  // failed measurements in a row
+}
+
+// Only used by the sampler goroutine
+var SensorAlerts *sensorAlerts = &sensorAlerts{}
+
+// Check a sample against the thresholds | reports if it is closer than ALERT_DISTANCE
+func (a *sensorAlerts) check(data SensorData) bool {
+	thresholds := PhoeniciaDigitalConfig.Config().Alerts
+	distance, maxErrors := thresholds.Alert_distance, thresholds.Alert_sensor_errors
+
+	if data.Status != statusSuccess {
+		a.errors++
+		if maxErrors > 0 && a.errors == maxErrors {
+			sensorAlertsRaised.Inc("sensor_errors")
+			PhoeniciaDigitalUtils.Logger.Warn("Sensor alert | Measurements keep failing", "failed", a.errors, "threshold", maxErrors)
+		}
+		return false
+	}
+
+	if maxErrors > 0 && a.errors >= maxErrors {
+		PhoeniciaDigitalUtils.Logger.Info("Sensor alert cleared | Measurements succeed again", "failed", a.errors)
+	}
+	a.errors = 0
+
+	close := distance > 0 && data.Distance < distance
+	switch {
+	case close && !a.close:
+		sensorAlertsRaised.Inc("distance")
+		PhoeniciaDigitalUtils.Logger.Warn("Sensor alert | Object closer than ALERT_DISTANCE", "distance", data.Distance, "threshold", distance)
+	case !close && a.close:
+		PhoeniciaDigitalUtils.Logger.Info("Sensor alert cleared | Nothing closer than ALERT_DISTANCE", "distance", data.Distance, "threshold", distance)
+	}
+	a.close = close
+	return close
+}
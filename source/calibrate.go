@@ -0,0 +1,262 @@
+// File: `Hardware Calibration File` source/calibrate.go
+package source
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Interactive calibration run on the terminal by `calibrate servo` & `calibrate sensor` | the prompts are written to out
+// & the answers read line by line from in | Nothing is saved the values found are returned to be set in the config
+// Admins can also apply known values to the running device with PUT /calibration | see HandleSetCalibration
+
+var errCalibrationAborted = errors.New("calibration aborted")
+
+const (
+	calibrationSamples  int           = 10                    // measurements averaged at every known distance
+	calibrationInterval time.Duration = 60 * time.Millisecond // the HC-SR04 needs ~60ms between measurements
+)
+
+// The ranges of SERVO_TRIM & SENSOR_SCALE | the same ones are checked when the config is loaded
+const (
+	maxServoTrim   float64 = 45
+	minSensorScale float64 = 0.000001
+	maxSensorScale float64 = 1000
+)
+
+// A float64 read by the running servo & sensor while PUT /calibration changes it
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *atomicFloat) Store(value float64) {
+	f.bits.Store(math.Float64bits(value))
+}
+
+func checkServoTrim(trim float64) error {
+	if math.IsNaN(trim) || math.Abs(trim) > maxServoTrim {
+		return fmt.Errorf("servo_trim must be in range -%g --> %g Degrees got: %g", maxServoTrim, maxServoTrim, trim)
+	}
+	return nil
+}
+
+func checkSensorScale(scale float64) error {
+	if math.IsNaN(scale) || scale < minSensorScale || scale > maxSensorScale {
+		return fmt.Errorf("sensor_scale must be in range %g --> %g got: %g", minSensorScale, maxSensorScale, scale)
+	}
+	return nil
+}
+
+// The calibration the servo & sensor run with
+type calibrationValues struct {
+	ServoTrim   float64 `json:"servo_trim"`
+	SensorScale float64 `json:"sensor_scale"`
+}
+
+func currentCalibration() calibrationValues {
+	return calibrationValues{ServoTrim: ServoMotor.trim.Load(), SensorScale: HCSR04.scale.Load()}
+}
+
+func HandleCalibration(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: currentCalibration()}
+}
+
+// Apply ?servo_trim=<Degrees> and/or ?sensor_scale=<factor> to the running servo & sensor | both are checked before
+// either is applied | Nothing is saved so set SERVO_TRIM & SENSOR_SCALE in the config to keep them after a restart
+func HandleSetCalibration(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	query := r.URL.Query()
+	if !query.Has("servo_trim") && !query.Has("sensor_scale") {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: "Set servo_trim and/or sensor_scale"}
+	}
+
+	values := currentCalibration()
+	for name, check := range map[string]func(float64) error{"servo_trim": checkServoTrim, "sensor_scale": checkSensorScale} {
+		if !query.Has(name) {
+			continue
+		}
+		value, err := strconv.ParseFloat(query.Get(name), 64)
+		if err != nil {
+			return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: fmt.Sprintf("Invalid %s: %s | must be a number", name, query.Get(name))}
+		}
+		if err := check(value); err != nil {
+			return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: err.Error()}
+		}
+		if name == "servo_trim" {
+			values.ServoTrim = value
+		} else {
+			values.SensorScale = value
+		}
+	}
+
+	if err := ServoMotor.SetTrim(values.ServoTrim); err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: err.Error()}
+	}
+	if err := HCSR04.SetScale(values.SensorScale); err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: err.Error()}
+	}
+
+	PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "Calibration changed | Set SERVO_TRIM & SENSOR_SCALE in the config to keep it after a restart", "servo_trim", values.ServoTrim, "sensor_scale", values.SensorScale)
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: currentCalibration()}
+}
+
+// SetTrim changes the SERVO_TRIM of the running servo | an idle servo moves to its position with the new trim while
+// a running move, scan or loiter picks it up on its next step
+func (s *servoMotor) SetTrim(trim float64) error {
+	if err := checkServoTrim(trim); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trim.Store(trim)
+	if s.connected && !s.loitering && !s.moving {
+		s.Motor.MoveTo(s.trimmed(s.currentPos)).Wait()
+	}
+	return nil
+}
+
+// SetScale changes the SENSOR_SCALE of the running sensor | applies from the next measurement
+func (h *hcsr04) SetScale(scale float64) error {
+	if err := checkSensorScale(scale); err != nil {
+		return err
+	}
+	h.scale.Store(scale)
+	return nil
+}
+
+// Read the next answer | EOF (ex: ctrl+D) aborts the calibration
+func readAnswer(scanner *bufio.Scanner, out io.Writer, prompt string) (string, error) {
+	fmt.Fprint(out, prompt)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", errCalibrationAborted
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+// Calibrate finds the SERVO_TRIM | the servo is moved to the angle that should point straight ahead (90 Degrees) & nudged until
+// it does | Returns the trim to set (the angle found - 90) | The servo must be initialized & not loitering or moving
+func (s *servoMotor) Calibrate(in io.Reader, out io.Writer) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loitering || s.moving {
+		return 0, fmt.Errorf("cannot calibrate while the servo is loitering or moving")
+	}
+
+	// The angles are sent as is | the current trim is only the starting point
+	angle := 90 + s.trim.Load()
+	s.Motor.SetSpeed(s.moveSpeed)
+	s.Motor.MoveTo(angle).Wait()
+
+	fmt.Fprintln(out, "Nudge the servo until the horn points straight ahead")
+	fmt.Fprintln(out, "  + / -   move 1 Degree    ++ / --   move 5 Degrees    <angle>   move to the angle")
+	fmt.Fprintln(out, "  enter   accept           q         abort")
+
+	scanner := bufio.NewScanner(in)
+	for {
+		answer, err := readAnswer(scanner, out, fmt.Sprintf("angle %.0f (trim %+.0f) > ", angle, angle-90))
+		if err != nil {
+			return 0, err
+		}
+
+		next := angle
+		switch answer {
+		case "":
+			s.Motor.MoveTo(s.trimmed(s.currentPos)).Wait()
+			return angle - 90, nil
+		case "q":
+			s.Motor.MoveTo(s.trimmed(s.currentPos)).Wait()
+			return 0, errCalibrationAborted
+		case "+":
+			next++
+		case "-":
+			next--
+		case "++":
+			next += 5
+		case "--":
+			next -= 5
+		default:
+			if next, err = strconv.ParseFloat(answer, 64); err != nil {
+				fmt.Fprintf(out, "unknown answer: %s\n", answer)
+				continue
+			}
+		}
+
+		if checkServoTrim(next-90) != nil {
+			fmt.Fprintf(out, "the trim must stay in the range -%g --> %g Degrees\n", maxServoTrim, maxServoTrim)
+			continue
+		}
+		angle = next
+		s.Motor.MoveTo(angle).Wait()
+	}
+}
+
+// Calibrate finds the SENSOR_SCALE | an object is placed at known distances & the scale that best maps the averaged
+// measurements onto them is returned (least squares through the origin) | The sensor must be initialized
+func (h *hcsr04) Calibrate(in io.Reader, out io.Writer) (float64, error) {
+	fmt.Fprintln(out, "Place a flat object in front of the sensor & type its distance in cm | enter once done, q to abort")
+
+	var measuredSquared, measuredByActual float64
+	scanner := bufio.NewScanner(in)
+	for points := 0; ; {
+		answer, err := readAnswer(scanner, out, fmt.Sprintf("distance #%d (cm) > ", points+1))
+		if err != nil {
+			return 0, err
+		}
+
+		if answer == "q" {
+			return 0, errCalibrationAborted
+		}
+
+		if answer == "" {
+			if points == 0 {
+				return 0, fmt.Errorf("no distance measured | at least one is needed")
+			}
+			return measuredByActual / measuredSquared, nil
+		}
+
+		actual, err := strconv.ParseFloat(answer, 64)
+		if err != nil || actual <= 0 {
+			fmt.Fprintf(out, "the distance must be a number > 0 got: %s\n", answer)
+			continue
+		}
+
+		measured, failed := 0.0, 0
+		for i := 0; i < calibrationSamples; i++ {
+			distance, err := h.echoDistance()
+			if err != nil {
+				failed++
+			}
+			measured += distance
+			time.Sleep(calibrationInterval)
+		}
+		measured /= float64(calibrationSamples)
+
+		if failed > 0 || measured <= 0 || math.IsNaN(measured) {
+			fmt.Fprintln(out, "no echo measured | check the wiring & the object then try again")
+			continue
+		}
+
+		fmt.Fprintf(out, "measured %.4f unscaled | scale for this distance %.6f\n", measured, actual/measured)
+		measuredSquared += measured * measured
+		measuredByActual += measured * actual
+		points++
+	}
+}
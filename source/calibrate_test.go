@@ -0,0 +1,43 @@
+// File: `Calibration Tests File` source/calibrate_test.go
+package source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The servo is not connected in the tests so a new trim is only stored
+func TestHandleSetCalibration(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTrim   float64
+		wantScale  float64
+	}{
+		{name: "both values", query: "servo_trim=-3.5&sensor_scale=1.02", wantStatus: http.StatusOK, wantTrim: -3.5, wantScale: 1.02},
+		{name: "only the trim", query: "servo_trim=4", wantStatus: http.StatusOK, wantTrim: 4, wantScale: 1},
+		{name: "only the scale", query: "sensor_scale=0.98", wantStatus: http.StatusOK, wantTrim: 0, wantScale: 0.98},
+		{name: "no values", query: "", wantStatus: http.StatusBadRequest, wantTrim: 0, wantScale: 1},
+		{name: "not a number", query: "servo_trim=left", wantStatus: http.StatusBadRequest, wantTrim: 0, wantScale: 1},
+		{name: "trim out of range", query: "servo_trim=46", wantStatus: http.StatusBadRequest, wantTrim: 0, wantScale: 1},
+		{name: "a bad scale applies neither value", query: "servo_trim=4&sensor_scale=0", wantStatus: http.StatusBadRequest, wantTrim: 0, wantScale: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ServoMotor.trim.Store(0)
+			HCSR04.scale.Store(1)
+
+			r := httptest.NewRequest(http.MethodPut, "/calibration?"+test.query, nil)
+			response := HandleSetCalibration(httptest.NewRecorder(), r)
+			if got := response.Status(); got != test.wantStatus {
+				t.Fatalf("status = %d want %d", got, test.wantStatus)
+			}
+			if got := currentCalibration(); got.ServoTrim != test.wantTrim || got.SensorScale != test.wantScale {
+				t.Errorf("calibration = %+v want trim %v & scale %v", got, test.wantTrim, test.wantScale)
+			}
+		})
+	}
+}
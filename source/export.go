@@ -0,0 +1,199 @@
+package source
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rows are flushed to the client every exportFlushRows rows so large exports start downloading right away
+const exportFlushRows int = 100
+
+func HandleExportReadings(w http.ResponseWriter, r *http.Request) {
+	exportRows(w, r, "export_readings", "readings")
+}
+
+func HandleExportScans(w http.ResponseWriter, r *http.Request) {
+	exportRows(w, r, "export_scan_frames", "scans")
+}
+
+// Stream the rows returned by the .sql file queryName as CSV or NDJSON row by row
+// The query receives the optional from, to & device filters as $1, $2 & $3 (NULL when not given)
+func exportRows(w http.ResponseWriter, r *http.Request, queryName string, fileName string) {
+	format, ok := exportFormat(r)
+	if !ok {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusNotAcceptable, PhoeniciaDigitalUtils.ApiError{Code: http.StatusNotAcceptable, Quote: fmt.Sprintf("Unsupported export format: %s | Use csv or ndjson", r.URL.Query().Get("format"))})
+		return
+	}
+
+	from, err := exportTime(r, "from")
+	if err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusBadRequest, PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: err.Error()})
+		return
+	}
+
+	to, err := exportTime(r, "to")
+	if err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusBadRequest, PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: err.Error()})
+		return
+	}
+
+	var device any
+	if value := r.URL.Query().Get("device"); value != "" {
+		device = value
+	}
+
+	if !PhoeniciaDigitalDatabase.PostgresEnabled() {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusServiceUnavailable, PhoeniciaDigitalUtils.ApiError{Code: http.StatusServiceUnavailable, Quote: "Exports require POSTGRES_ENABLED=true"})
+		return
+	}
+
+	// Failures are logged with the request ID by QueryRows | POSTGRES_QUERY_TIMEOUT only covers starting the query so
+	// the stream below runs as long as the client reads it
+	rows, err := PhoeniciaDigitalDatabase.Postgres.QueryRows(r.Context(), queryName, from, to, device)
+	if err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusInternalServerError, PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: "Failed to query export"})
+		return
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusInternalServerError, PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: "Failed to read export columns"})
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", fileName, format))
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	csvWriter := csv.NewWriter(w)
+	jsonEncoder := json.NewEncoder(w)
+
+	if format == "csv" {
+		csvWriter.Write(columns)
+	}
+
+	// Every value is scanned into a generic destination so the same streaming code serves every export
+	values := make([]any, len(columns))
+	destinations := make([]any, len(columns))
+	for i := range values {
+		destinations[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(destinations...); err != nil {
+			PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Error scanning export row", "query", queryName, "rows", count, "error", err)
+			abortExport()
+		}
+
+		if format == "csv" {
+			record := make([]string, len(values))
+			for i, value := range values {
+				record[i] = exportCSVValue(value)
+			}
+			err = csvWriter.Write(record)
+		} else {
+			object := make(map[string]any, len(columns))
+			for i, column := range columns {
+				if bytes, ok := values[i].([]byte); ok {
+					object[column] = string(bytes)
+				} else {
+					object[column] = values[i]
+				}
+			}
+			err = jsonEncoder.Encode(object)
+		}
+
+		// The client most likely went away so there is nobody left to stream to
+		if err != nil {
+			PhoeniciaDigitalUtils.Logger.WarnContext(r.Context(), "Error streaming export", "query", queryName, "rows", count, "error", err)
+			abortExport()
+		}
+
+		count++
+		if count%exportFlushRows == 0 {
+			csvWriter.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Error iterating export rows", "query", queryName, "rows", count, "error", err)
+		abortExport()
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		PhoeniciaDigitalUtils.Logger.WarnContext(r.Context(), "Error streaming export", "query", queryName, "rows", count, "error", err)
+		abortExport()
+	}
+}
+
+// The 200 status & part of the rows are already sent once the export fails mid-stream so the connection is aborted
+// instead (the client sees an incomplete response rather than a silently truncated file)
+func abortExport() {
+	panic(http.ErrAbortHandler)
+}
+
+// Pick the export format from ?format= first & fall back to the Accept header | CSV by default
+func exportFormat(r *http.Request) (string, bool) {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "csv":
+		return "csv", true
+	case "ndjson", "jsonl":
+		return "ndjson", true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "application/x-ndjson") || strings.Contains(accept, "application/ndjson") {
+		return "ndjson", true
+	}
+	return "csv", true
+}
+
+// Parse an optional RFC3339 time query parameter returning nil (SQL NULL) when it was not given
+func exportTime(r *http.Request, key string) (any, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s time: %s | Use RFC3339 e.g. 2024-01-02T15:04:05Z", key, value)
+	}
+	return parsed, nil
+}
+
+func exportCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
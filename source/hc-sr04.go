@@ -4,10 +4,9 @@ import (
 	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
 	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
 	"encoding/json"
-	"fmt"
-	"log"
+	"errors"
 	"net/http"
-	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,58 +18,76 @@ type hcsr04 struct {
 	Echo        rpio.Pin
 	SpeedOfWave float32
 	pulseWidth  time.Duration
+	scale       atomicFloat // SENSOR_SCALE found with `calibrate sensor` | can change with PUT /calibration
+	gpioOpen    bool
+	lastReading atomic.Int64 // Unix nano time of the last successful measurement
+	streams     atomic.Int32 // Number of websocket clients currently streaming live measurements
 }
 
 // SensorData represents the data structure for the sensor's output
 type SensorData struct {
 	Distance float64 `json:"distance"`
 	Status   string  `json:"status"`
+	Alert    bool    `json:"alert"` // the distance is closer than ALERT_DISTANCE
 }
 
 // WebSocket upgrader for handling HTTP requests to WebSocket connections
+// Origins are checked against the same CORS policy as every other route
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin: func(r *http.Request) bool { return PhoeniciaDigitalUtils.CORS.CheckOrigin(r) },
 }
 
 var HCSR04 *hcsr04 = &hcsr04{}
 
+// Largest message read from a websocket client | the streams only send so clients have nothing to say
+const websocketReadLimit int64 = 512
+
+// Read a websocket client until it disconnects | reading is what answers its pings & handles its close frame so every
+// stream runs one while it sends | The returned channel is closed once the client is gone
+func readPump(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+	conn.SetReadLimit(websocketReadLimit)
+
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return closed
+}
+
+// The HC-SR04 holds the echo high for ~38ms when nothing reflects the pulse | a pin still waiting past this deadline
+// means the sensor is unplugged or miswired so the measurement fails instead of hanging the sampler
+const echoTimeout time.Duration = 50 * time.Millisecond
+
+// The status of a measurement as streamed to the websocket clients & stored with the readings
+const (
+	statusSuccess    string = "Success"
+	statusError      string = "Error measuring distance"
+	statusOutOfRange string = "Out of range"
+)
+
+var (
+	errNoEcho      = errors.New("no echo pulse received")
+	errEchoTooLong = errors.New("echo pulse never ended")
+)
+
 func (h *hcsr04) InitializeUltrasonicSensor() {
 
 	// Initialize GPIO
 	err := rpio.Open()
 	if err != nil {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Failed to open GPIO: %v", err))
-		log.Fatalf("Failed to open GPIO: %v", err)
+		PhoeniciaDigitalUtils.Fatal("Failed to open GPIO", "error", err)
 	}
 	// defer rpio.Close()
+	h.gpioOpen = true
 
-	// Check Pin Conversion from the .env file (should be actual numbers and in range of the raspberry pi zero w pins)
-	// If an issue occured with conversion the program wont run!
-	trigPin, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Pins.TriggerPin)
-	if err != nil {
-		PhoeniciaDigitalUtils.Log("Trigger Pin Value in .env file is an invalid pin number")
-		log.Fatalf("Trigger Pin Value in .env file is an invalid pin number")
-	}
-
-	// Check Pin Conversion from the .env file (should be actual numbers and in range of the raspberry pi zero w pins)
-	// If an issue occured with conversion the program wont run!
-	echoPin, err := strconv.Atoi(PhoeniciaDigitalConfig.Config.Pins.EchoPin)
-	if err != nil {
-		PhoeniciaDigitalUtils.Log("Echo Pin Value in .env file is an invalid pin number")
-		log.Fatalf("Echo Pin Value in .env file is an invalid pin number")
-	}
-
-	// Make sure the Trigger & Echo pins are not the same & make sure the Trigger & Echo pins are in the GPIO range map of a raspberry pi zero w v1
-	if trigPin == echoPin {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Echo pin: %d, & Trigger pin: %d, CANT be assigned to the same pin", echoPin, trigPin))
-		log.Fatalf("Echo pin: %d, & Trigger pin: %d, CANT be assigned to the same pin", echoPin, trigPin)
-	} else if trigPin < 2 || trigPin > 27 {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Trigger pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", trigPin))
-		log.Fatalf("Trigger pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", trigPin)
-	} else if echoPin < 2 || echoPin > 27 {
-		PhoeniciaDigitalUtils.Log(fmt.Sprintf("Echo pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", echoPin))
-		log.Fatalf("Echo pin: %d, out of GPIO map range [2 -> 27] | Please Change it in the ~/config/.env file", echoPin)
-	}
+	// The pins are typed, range checked (GPIO map [2 -> 27]) & checked to be different when the config is loaded
+	trigPin := PhoeniciaDigitalConfig.Config().Pins.TriggerPin
+	echoPin := PhoeniciaDigitalConfig.Config().Pins.EchoPin
 
 	// Set the proper Trigger pin map to the struct HCSR04 & Make the Trigger pin an output Pin
 	h.Trigger = rpio.Pin(trigPin)
@@ -83,13 +100,37 @@ func (h *hcsr04) InitializeUltrasonicSensor() {
 	// Assign other variables that will be linked to the hc-sr04
 	h.SpeedOfWave = 0.0343
 	h.pulseWidth = 10 * time.Microsecond
-	PhoeniciaDigitalUtils.Log(fmt.Sprintf("Initialized With Trigger Pin: %d, Echo Pin: %d", trigPin, echoPin))
-	log.Printf("Initialized With Trigger Pin: %d, Echo Pin: %d", trigPin, echoPin)
+	h.scale.Store(PhoeniciaDigitalConfig.Config().Calibration.Sensor_scale)
+	PhoeniciaDigitalUtils.Logger.Info("Initialized Ultrasonic Sensor", "trigger_pin", trigPin, "echo_pin", echoPin, "scale", h.scale.Load())
+
+}
+
+// Function to measure distance in centimeters | the distance is -1 when the measurement failed
+func (h *hcsr04) MeasureDistance() (float64, error) {
+	measureStart := time.Now()
 
+	distance, err := h.echoDistance()
+	if err != nil {
+		distance = -1
+	} else {
+		distance *= h.scale.Load()
+	}
+	observeMeasurement(distance, err, time.Since(measureStart))
+	if sensorStatus(distance, err) == statusSuccess {
+		h.lastReading.Store(time.Now().UnixNano())
+	}
+	return distance, err
 }
 
-// Function to measure distance in centimeters
-func (h *hcsr04) MeasureDistance() float64 {
+// A single measurement with its status | as streamed to the websocket clients
+func (h *hcsr04) Measure() SensorData {
+	distance, err := h.MeasureDistance()
+	return SensorData{Distance: distance, Status: sensorStatus(distance, err)}
+}
+
+// The distance from the width of the echo pulse before the calibration scale is applied | fails when the echo pulse
+// does not start or end within echoTimeout
+func (h *hcsr04) echoDistance() (float64, error) {
 	// Send a pulse to the trigger pin
 	h.Trigger.Low()
 	time.Sleep(h.pulseWidth) // Delay to ensure pulse width is valid
@@ -98,21 +139,58 @@ func (h *hcsr04) MeasureDistance() float64 {
 	h.Trigger.Low()
 
 	// Wait for the echo pulse to start
+	deadline := time.Now().Add(echoTimeout)
 	for h.Echo.Read() == rpio.Low {
+		if time.Now().After(deadline) {
+			return 0, errNoEcho
+		}
 	}
 
 	// Record the start time
 	start := time.Now()
 
 	// Wait for the echo pulse to end
+	deadline = start.Add(echoTimeout)
 	for h.Echo.Read() == rpio.High {
+		if time.Now().After(deadline) {
+			return 0, errEchoTooLong
+		}
 	}
 
 	duration := time.Since(start)
 
 	// Calculate distance in cm
-	distance := (float64(duration) * float64(h.SpeedOfWave)) / 2 // Convert to cm
-	return distance
+	return (float64(duration) * float64(h.SpeedOfWave)) / 2, nil // Convert to cm
+}
+
+// Reports if the GPIO memory map was opened for the sensor pins
+func (h *hcsr04) GPIOOpened() bool {
+	return h.gpioOpen
+}
+
+// Returns the time of the last successful measurement | false if no measurement succeeded yet
+func (h *hcsr04) LastReading() (time.Time, bool) {
+	if nano := h.lastReading.Load(); nano != 0 {
+		return time.Unix(0, nano), true
+	}
+	return time.Time{}, false
+}
+
+// Returns the number of websocket clients currently streaming live measurements
+func (h *hcsr04) ActiveStreams() int {
+	return int(h.streams.Load())
+}
+
+// The status reported with a measurement | a failed measurement is an error & a distance the HC-SR04 cannot measure
+// (outside 2cm -> 400cm) is out of range | only successful ones refresh the last reading of /readyz
+func sensorStatus(distance float64, err error) string {
+	switch {
+	case err != nil:
+		return statusError
+	case distance < sensorMinDistance || distance > sensorMaxDistance:
+		return statusOutOfRange
+	}
+	return statusSuccess
 }
 
 // WebSocket handler for handling connections and sending data to clients
@@ -120,52 +198,50 @@ func HandleMeasureDistance(w http.ResponseWriter, r *http.Request) {
 	// Upgrade HTTP connection to WebSocket connection
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("Upgrade failed:", err)
+		PhoeniciaDigitalUtils.Logger.WarnContext(r.Context(), "Upgrade failed", "error", err)
 		return
 		// return PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: err.Error()}
 	}
 	defer conn.Close()
 
-	log.Println("New WebSocket client connected")
+	PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "New WebSocket client connected", "remote", r.RemoteAddr)
+	websocketClients.Inc("live")
+	defer websocketClients.Dec("live")
+	HCSR04.streams.Add(1)
+	defer HCSR04.streams.Add(-1)
 
-	// Start measuring and sending data to the WebSocket client in a goroutine
-	for {
-		// Measure the distance
-		distance := HCSR04.MeasureDistance()
+	// Every client receives the samples of the single sampler | see ./sampler.go
+	samples := SensorSampler.Subscribe()
+	defer SensorSampler.Unsubscribe(samples)
 
-		// Prepare the response struct
-		sensorData := SensorData{
-			Distance: distance,
-		}
+	// A client leaving between two samples stops the stream right away instead of on the next write
+	closed := readPump(conn)
 
-		// If there's an error in the measurement, set the status as an error
-		if distance == -1 {
-			sensorData.Status = "Error measuring distance"
-		} else {
-			sensorData.Status = "Success"
+	for {
+		var sensorData SensorData
+		select {
+		case <-closed:
+			PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "WebSocket client disconnected", "remote", r.RemoteAddr)
+			return
+		case <-r.Context().Done():
+			return
+		case sensorData = <-samples:
 		}
 
 		// Marshal the struct to JSON
 		jsonData, err := json.Marshal(sensorData)
 		if err != nil {
-			log.Println("Error marshaling JSON:", err)
-			break
+			PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Error marshaling JSON", "error", err)
+			return
 		}
 
 		// Send the JSON data to the WebSocket client
 		err = conn.WriteMessage(websocket.TextMessage, jsonData)
 		if err != nil {
-			log.Println("Error sending data:", err)
-			break
+			PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "WebSocket client disconnected", "remote", r.RemoteAddr, "error", err)
+			return
 		}
-
-		// Sleep before taking the next measurement
-		time.Sleep(1 * time.Second) // Measure every 1 second
 	}
 
 	// return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: "Websocket Closed"}
 }
-
-func init() {
-	HCSR04.InitializeUltrasonicSensor()
-}
@@ -0,0 +1,113 @@
+// File: `Ultrasonic Sensor Tests File` source/hc-sr04_test.go
+package source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSensorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance float64
+		err      error
+		want     string
+	}{
+		{name: "distance in range", distance: 120.5, want: statusSuccess},
+		{name: "closest distance measured", distance: sensorMinDistance, want: statusSuccess},
+		{name: "farthest distance measured", distance: sensorMaxDistance, want: statusSuccess},
+		{name: "closer than the sensor can measure", distance: 1.2, want: statusOutOfRange},
+		{name: "farther than the sensor can measure", distance: 650, want: statusOutOfRange},
+		{name: "no echo pulse", distance: -1, err: errNoEcho, want: statusError},
+		{name: "echo pulse never ended", distance: -1, err: errEchoTooLong, want: statusError},
+		{name: "the error wins over an in range distance", distance: 120.5, err: errNoEcho, want: statusError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sensorStatus(test.distance, test.err); got != test.want {
+				t.Errorf("sensorStatus(%v, %v) = %q want %q", test.distance, test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSensorAlertsCountFailedMeasurements(t *testing.T) {
+	alerts := &sensorAlerts{}
+	failed := SensorData{Distance: -1, Status: sensorStatus(-1, errNoEcho)}
+	outOfRange := SensorData{Distance: 650, Status: sensorStatus(650, nil)}
+
+	alerts.check(failed)
+	alerts.check(outOfRange)
+	if alerts.errors != 2 {
+		t.Fatalf("errors = %d want 2 | failed & out of range measurements both count", alerts.errors)
+	}
+
+	alerts.check(SensorData{Distance: 120, Status: sensorStatus(120, nil)})
+	if alerts.errors != 0 {
+		t.Errorf("errors = %d want 0 once a measurement succeeds", alerts.errors)
+	}
+}
+
+func TestReadPumpClosesOnceTheClientLeaves(t *testing.T) {
+	closed := make(chan (<-chan struct{}), 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		pump := readPump(conn)
+		closed <- pump
+		<-pump
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pump := <-closed
+
+	// Pings are answered & other messages dropped while the client stays
+	pong := make(chan struct{}, 1)
+	client.SetPongHandler(func(string) error { pong <- struct{}{}; return nil })
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	if err := client.WriteMessage(websocket.TextMessage, []byte("ignored")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-pong:
+	case <-time.After(time.Second):
+		t.Fatal("the ping of the client was not answered")
+	}
+	select {
+	case <-pump:
+		t.Fatal("the pump stopped while the client is still connected")
+	default:
+	}
+
+	client.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	client.Close()
+	select {
+	case <-pump:
+	case <-time.After(time.Second):
+		t.Fatal("the pump did not stop once the client left")
+	}
+}
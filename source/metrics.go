@@ -0,0 +1,57 @@
+package source
+
+import (
+	PhoeniciaDigitalMetrics "Phoenicia-Digital-Base-API/base/metrics"
+	"math"
+	"time"
+)
+
+// The HC-SR04 can only measure distances in range 2cm -> 400cm anything outside is counted as an error
+const (
+	sensorMinDistance float64 = 2
+	sensorMaxDistance float64 = 400
+)
+
+var (
+	websocketClients = PhoeniciaDigitalMetrics.NewGauge("pd_websocket_clients", "Number of connected websocket clients.", "stream")
+
+	sensorSamples = PhoeniciaDigitalMetrics.NewCounter("pd_sensor_samples_total", "Number of distance measurements taken by the HC-SR04 sensor.")
+
+	sensorErrors = PhoeniciaDigitalMetrics.NewCounter("pd_sensor_measurement_errors_total", "Number of failed distance measurements by kind.", "kind")
+
+	sensorAlertsRaised = PhoeniciaDigitalMetrics.NewCounter("pd_sensor_alerts_total", "Number of alerts raised by the ALERT_* thresholds by kind.", "kind")
+
+	sensorDuration = PhoeniciaDigitalMetrics.NewHistogram("pd_sensor_measurement_duration_seconds", "Time taken by a single distance measurement.",
+		[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1})
+
+	storeErrors = PhoeniciaDigitalMetrics.NewCounter("pd_store_errors_total", "Number of readings & scan frames that failed to be stored by table.", "table")
+
+	servoMoves = PhoeniciaDigitalMetrics.NewCounter("pd_servo_moves_total", "Number of servo movements by origin.", "origin")
+
+	servoDegrees = PhoeniciaDigitalMetrics.NewCounter("pd_servo_degrees_travelled_total", "Total degrees travelled by the servo.")
+
+	servoLoitering = PhoeniciaDigitalMetrics.NewGauge("pd_servo_loitering", "1 while the servo is loitering 0 otherwise.")
+
+	servoLimited = PhoeniciaDigitalMetrics.NewCounter("pd_servo_motion_limited_total", "Number of servo commands refused by the motion rate limit.")
+
+	servoCoalesced = PhoeniciaDigitalMetrics.NewCounter("pd_servo_motion_coalesced_total", "Number of servo commands merged into a move that was already running.")
+)
+
+// Record a finished distance measurement classifying failed ones by kind | err is the one returned by echoDistance
+func observeMeasurement(distance float64, err error, took time.Duration) {
+	sensorSamples.Inc()
+	sensorDuration.Observe(took.Seconds())
+
+	switch {
+	case err != nil:
+		sensorErrors.Inc("failed")
+	case distance < sensorMinDistance || distance > sensorMaxDistance:
+		sensorErrors.Inc("out_of_range")
+	}
+}
+
+// Record a servo movement from one angle to another
+func observeServoMove(origin string, from float64, to float64) {
+	servoMoves.Inc(origin)
+	servoDegrees.Add(math.Abs(to - from))
+}
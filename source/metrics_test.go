@@ -0,0 +1,67 @@
+// File: `Source Metrics Tests File` source/metrics_test.go
+package source
+
+import (
+	PhoeniciaDigitalMetrics "Phoenicia-Digital-Base-API/base/metrics"
+	"bufio"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The value of a single series as exposed on /metrics | 0 when it was never updated
+func exposedValue(t *testing.T, series string) float64 {
+	t.Helper()
+	var text strings.Builder
+	PhoeniciaDigitalMetrics.WriteText(&text)
+
+	scanner := bufio.NewScanner(strings.NewReader(text.String()))
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), series+" ")
+		if !found {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("series %s has a value that is not a number: %s", series, value)
+		}
+		return parsed
+	}
+	return 0
+}
+
+func TestObserveMeasurementErrors(t *testing.T) {
+	const (
+		failed     = `pd_sensor_measurement_errors_total{kind="failed"}`
+		outOfRange = `pd_sensor_measurement_errors_total{kind="out_of_range"}`
+	)
+
+	tests := []struct {
+		name           string
+		distance       float64
+		err            error
+		wantFailed     float64
+		wantOutOfRange float64
+	}{
+		{name: "successful measurement", distance: 120},
+		{name: "no echo pulse", distance: -1, err: errNoEcho, wantFailed: 1},
+		{name: "echo pulse never ended", distance: -1, err: errEchoTooLong, wantFailed: 1},
+		{name: "closer than the sensor can measure", distance: 1, wantOutOfRange: 1},
+		{name: "farther than the sensor can measure", distance: 650, wantOutOfRange: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			failedBefore, outOfRangeBefore := exposedValue(t, failed), exposedValue(t, outOfRange)
+			observeMeasurement(test.distance, test.err, time.Millisecond)
+
+			if got := exposedValue(t, failed) - failedBefore; got != test.wantFailed {
+				t.Errorf("failed went up by %v want %v", got, test.wantFailed)
+			}
+			if got := exposedValue(t, outOfRange) - outOfRangeBefore; got != test.wantOutOfRange {
+				t.Errorf("out_of_range went up by %v want %v", got, test.wantOutOfRange)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package source
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The folder where every recorded session is stored as a `<name>.ndjson` file
+// The first line of every file is the recordingHeader & every line after it is a recordingFrame
+const recordingsDir string = "./recordings"
+
+// Recording names end up as file names so they are limited to a safe set of characters
+var recordingNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type sessionRecorder struct {
+	mu        sync.Mutex
+	recording atomic.Bool // read by the sampler without sr.mu
+	file      *os.File
+	writer    *bufio.Writer
+	encoder   *json.Encoder
+	name      string
+	startedAt time.Time
+	frames    int
+}
+
+// The first line of a recording file describing the session
+type recordingHeader struct {
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// A single captured event of a session | Offset is the time elapsed since the recording started
+// Kind is either "sensor" (a sample sent to the websocket clients) or "servo" (a servo position change)
+type recordingFrame struct {
+	Offset time.Duration `json:"offset_ns"`
+	Kind   string        `json:"kind"`
+	Sensor *SensorData   `json:"sensor,omitempty"`
+	Degree float64       `json:"degree"`
+}
+
+// The summary of a recording returned by the recording endpoints
+type recordingInfo struct {
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+	Duration  float64   `json:"duration_seconds"`
+	Frames    int       `json:"frames"`
+	Size      int64     `json:"size_bytes"`
+}
+
+var SessionRecorder *sessionRecorder = &sessionRecorder{}
+
+func HandleStartRecording(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = fmt.Sprintf("session-%s", time.Now().Format("20060102-150405"))
+	}
+
+	info, err := SessionRecorder.Start(r.Context(), name)
+	if err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusConflict, Quote: err.Error()}
+	}
+
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusCreated, Quote: info}
+}
+
+func HandleStopRecording(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	info, err := SessionRecorder.Stop(r.Context())
+	if err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusConflict, Quote: err.Error()}
+	}
+
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: info}
+}
+
+func HandleListRecordings(w http.ResponseWriter, r *http.Request) PhoeniciaDigitalUtils.PhoeniciaDigitalResponse {
+	recordings, err := listRecordings()
+	if err != nil {
+		return PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: "Failed to list recordings"}
+	}
+
+	return PhoeniciaDigitalUtils.ApiSuccess{Code: http.StatusOK, Quote: recordings}
+}
+
+// WebSocket handler replaying a recording with the same messages & timing HandleMeasureDistance uses
+// The speed query parameter scales the timing (1 = real time, 2 = twice as fast, 10 = ten times as fast)
+func HandleReplayRecording(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !recordingNamePattern.MatchString(name) {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusBadRequest, PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: fmt.Sprintf("Invalid recording name: %s", name)})
+		return
+	}
+
+	speed := 1.0
+	if value := r.URL.Query().Get("speed"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err != nil || parsed <= 0 || parsed > 100 {
+			PhoeniciaDigitalUtils.SendJSON(w, http.StatusBadRequest, PhoeniciaDigitalUtils.ApiError{Code: http.StatusBadRequest, Quote: fmt.Sprintf("Invalid replay speed: %s | must be a number in range (0 -> 100]", value)})
+			return
+		} else {
+			speed = parsed
+		}
+	}
+
+	file, err := os.Open(recordingPath(name))
+	if err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusNotFound, PhoeniciaDigitalUtils.ApiError{Code: http.StatusNotFound, Quote: fmt.Sprintf("Recording %s not found", name)})
+		return
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	var header recordingHeader
+	if err := decoder.Decode(&header); err != nil {
+		PhoeniciaDigitalUtils.SendJSON(w, http.StatusInternalServerError, PhoeniciaDigitalUtils.ApiError{Code: http.StatusInternalServerError, Quote: fmt.Sprintf("Recording %s is corrupted", name)})
+		return
+	}
+
+	// Upgrade HTTP connection to WebSocket connection
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		PhoeniciaDigitalUtils.Logger.WarnContext(r.Context(), "Upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "New WebSocket client connected | Replaying recording", "remote", r.RemoteAddr, "recording", name, "speed", speed)
+	websocketClients.Inc("replay")
+	defer websocketClients.Dec("replay")
+
+	// A client leaving while the replay waits for the next frame stops it right away | see readPump
+	closed := readPump(conn)
+
+	var previous time.Duration
+	for {
+		var frame recordingFrame
+		if err := decoder.Decode(&frame); err != nil {
+			if err != io.EOF {
+				PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Error reading recording", "recording", name, "error", err)
+			}
+			break
+		}
+
+		// Only sensor samples are part of the websocket protocol servo frames are kept for debugging
+		if frame.Kind != "sensor" || frame.Sensor == nil {
+			continue
+		}
+
+		// Wait the same time that passed between the two samples when they were recorded scaled by speed
+		wait := time.NewTimer(time.Duration(float64(frame.Offset-previous) / speed))
+		select {
+		case <-wait.C:
+		case <-closed:
+			wait.Stop()
+			PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "WebSocket client disconnected", "remote", r.RemoteAddr, "recording", name)
+			return
+		case <-r.Context().Done():
+			wait.Stop()
+			return
+		}
+		previous = frame.Offset
+
+		jsonData, err := json.Marshal(frame.Sensor)
+		if err != nil {
+			PhoeniciaDigitalUtils.Logger.ErrorContext(r.Context(), "Error marshaling JSON", "error", err)
+			break
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, jsonData); err != nil {
+			PhoeniciaDigitalUtils.Logger.InfoContext(r.Context(), "WebSocket client disconnected", "remote", r.RemoteAddr, "recording", name, "error", err)
+			break
+		}
+	}
+}
+
+// Start a new recording session | only one session can be recorded at a time
+func (sr *sessionRecorder) Start(ctx context.Context, name string) (recordingInfo, error) {
+	if !recordingNamePattern.MatchString(name) {
+		return recordingInfo{}, fmt.Errorf("invalid recording name: %s | only letters, digits, - and _ are allowed (max 64)", name)
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if sr.file != nil {
+		return recordingInfo{}, fmt.Errorf("already recording session: %s", sr.name)
+	}
+
+	if err := os.MkdirAll(recordingsDir, 0755); err != nil {
+		return recordingInfo{}, err
+	}
+
+	file, err := os.OpenFile(recordingPath(name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return recordingInfo{}, fmt.Errorf("recording %s already exists", name)
+		}
+		return recordingInfo{}, err
+	}
+
+	sr.file = file
+	sr.writer = bufio.NewWriter(file)
+	sr.encoder = json.NewEncoder(sr.writer)
+	sr.name = name
+	sr.startedAt = time.Now()
+	sr.frames = 0
+
+	if err := sr.encoder.Encode(recordingHeader{Name: name, StartedAt: sr.startedAt}); err != nil {
+		sr.close()
+		return recordingInfo{}, err
+	}
+
+	// The samples are taken by the sampler even when no websocket client is connected
+	sr.recording.Store(true)
+	SensorSampler.wake()
+
+	PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Started recording session", "recording", name)
+
+	return recordingInfo{Name: name, StartedAt: sr.startedAt}, nil
+}
+
+// Stop the current recording session flushing it to disk & returning its summary
+func (sr *sessionRecorder) Stop(ctx context.Context) (recordingInfo, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if sr.file == nil {
+		return recordingInfo{}, fmt.Errorf("no session is being recorded")
+	}
+
+	info := recordingInfo{
+		Name:      sr.name,
+		StartedAt: sr.startedAt,
+		Duration:  time.Since(sr.startedAt).Seconds(),
+		Frames:    sr.frames,
+	}
+
+	if err := sr.close(); err != nil {
+		return recordingInfo{}, err
+	}
+
+	if stat, err := os.Stat(recordingPath(info.Name)); err == nil {
+		info.Size = stat.Size()
+	}
+
+	PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Stopped recording session", "recording", info.Name, "frames", info.Frames)
+
+	return info, nil
+}
+
+// Reports if a session is being recorded
+func (sr *sessionRecorder) Recording() bool {
+	return sr.recording.Load()
+}
+
+// Capture a sample of the sampler (the one sent to every websocket client) along with the current servo position
+func (sr *sessionRecorder) CaptureSensor(data SensorData) {
+	sr.capture(recordingFrame{Kind: "sensor", Sensor: &data, Degree: ServoMotor.Position()})
+}
+
+// Capture a change of the servo position
+func (sr *sessionRecorder) CaptureServo(degree float64) {
+	sr.capture(recordingFrame{Kind: "servo", Degree: degree})
+}
+
+func (sr *sessionRecorder) capture(frame recordingFrame) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if sr.file == nil {
+		return
+	}
+
+	frame.Offset = time.Since(sr.startedAt)
+	if err := sr.encoder.Encode(frame); err != nil {
+		PhoeniciaDigitalUtils.Logger.Error("Failed to record frame for session", "recording", sr.name, "error", err)
+		return
+	}
+	sr.frames++
+}
+
+// Flush & close the recording file | the caller must hold sr.mu
+func (sr *sessionRecorder) close() error {
+	flushErr := sr.writer.Flush()
+	closeErr := sr.file.Close()
+
+	sr.file = nil
+	sr.writer = nil
+	sr.encoder = nil
+	sr.recording.Store(false)
+
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+func recordingPath(name string) string {
+	return filepath.Join(recordingsDir, fmt.Sprintf("%s.ndjson", name))
+}
+
+// List every recording in the recordings folder sorted by start time
+func listRecordings() ([]recordingInfo, error) {
+	entries, err := os.ReadDir(recordingsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []recordingInfo{}, nil
+		}
+		return nil, err
+	}
+
+	recordings := []recordingInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".ndjson" {
+			continue
+		}
+
+		if info, err := readRecordingInfo(filepath.Join(recordingsDir, entry.Name())); err != nil {
+			PhoeniciaDigitalUtils.Logger.Warn("Skipping unreadable recording", "file", entry.Name(), "error", err)
+		} else {
+			recordings = append(recordings, info)
+		}
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.Before(recordings[j].StartedAt)
+	})
+
+	return recordings, nil
+}
+
+func readRecordingInfo(path string) (recordingInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return recordingInfo{}, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	var header recordingHeader
+	if err := decoder.Decode(&header); err != nil {
+		return recordingInfo{}, err
+	}
+
+	info := recordingInfo{Name: header.Name, StartedAt: header.StartedAt}
+	for {
+		var frame recordingFrame
+		if err := decoder.Decode(&frame); err != nil {
+			// A recording still being written may end in a partial line so only the complete frames are counted
+			break
+		}
+		info.Frames++
+		info.Duration = frame.Offset.Seconds()
+	}
+
+	if stat, err := file.Stat(); err == nil {
+		info.Size = stat.Size()
+	}
+
+	return info, nil
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"sync"
+	"time"
+)
+
+// The sensor is measured by a single sampler whatever the number of websocket clients | every sample is recorded
+// once & sent to every client so a recording holds the stream exactly as one client saw it
+// The sampler runs while a client is connected or a session is being recorded & stops once neither is left
+
+// Time between two samples of the live stream
+const sampleInterval time.Duration = 1 * time.Second
+
+type sensorSampler struct {
+	mu          sync.Mutex
+	subscribers map[chan SensorData]struct{}
+	running     bool
+}
+
+var SensorSampler *sensorSampler = &sensorSampler{subscribers: map[chan SensorData]struct{}{}}
+
+// Receive every sample from now on | DONT FORGET TO defer Unsubscribe
+// A client too slow to take a sample before the next one is taken misses it instead of delaying the others
+func (s *sensorSampler) Subscribe() chan SensorData {
+	samples := make(chan SensorData, 1)
+
+	s.mu.Lock()
+	s.subscribers[samples] = struct{}{}
+	s.mu.Unlock()
+
+	s.wake()
+	return samples
+}
+
+func (s *sensorSampler) Unsubscribe(samples chan SensorData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, samples)
+}
+
+// Start the sampler unless it is already running | called when a client subscribes & when a recording starts
+func (s *sensorSampler) wake() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	go s.run()
+}
+
+func (s *sensorSampler) run() {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		sensorData := HCSR04.Measure()
+		sensorData.Alert = SensorAlerts.check(sensorData)
+
+		// Capture the sample in case a session is being recorded & queue it to be stored for exports
+		SessionRecorder.CaptureSensor(sensorData)
+		storeReading(sensorData, ServoMotor.Position())
+
+		s.mu.Lock()
+		for samples := range s.subscribers {
+			select {
+			case samples <- sensorData:
+			default:
+			}
+		}
+		s.mu.Unlock()
+
+		<-ticker.C
+
+		// Checked under s.mu so a client subscribing or a recording starting right now either keeps the sampler
+		// running or finds it stopped & wakes it again
+		s.mu.Lock()
+		if len(s.subscribers) == 0 && !SessionRecorder.Recording() {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+	}
+}
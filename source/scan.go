@@ -0,0 +1,95 @@
+package source
+
+import (
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Time given to the servo to settle on an angle before the sensor is triggered
+const scanSettleTime time.Duration = 50 * time.Millisecond
+
+// A single measurement taken at one angle of a sweep
+type scanFrame struct {
+	ScanID   string    `json:"scan_id"`
+	TakenAt  time.Time `json:"taken_at"`
+	Degree   float64   `json:"degree"`
+	Distance float64   `json:"distance"`
+	Status   string    `json:"status"`
+}
+
+// Sweep the servo from 0 to 180 Degrees (run by `main scan`) in steps of rotateDegree measuring the distance at every step
+// Every frame is stored (if a Postgres Database is implemented) & the servo returns to its position once done
+// Rotations requested during the sweep are coalesced into the position it returns to
+func (s *servoMotor) Scan(ctx context.Context) ([]scanFrame, error) {
+	s.mu.Lock()
+	if s.loitering {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("cannot scan while loitering")
+	}
+
+	if s.moving {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("cannot scan while the servo is moving")
+	}
+
+	step := s.rotateDegree
+	if step <= 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("cannot scan with a rotation degree of %d", step)
+	}
+
+	if err := s.takeMotion(); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.moving = true
+	previous, speed := s.currentPos, s.moveSpeed
+	s.mu.Unlock()
+
+	scanID := fmt.Sprintf("scan-%d", time.Now().UnixNano())
+	frames := make([]scanFrame, 0, 180/step+1)
+
+	s.Motor.SetSpeed(speed)
+	for degree := 0; degree <= 180; degree += step {
+		s.Motor.MoveTo(s.trimmed(float64(degree))).Wait()
+		observeServoMove("scan", previous, float64(degree))
+		previous = float64(degree)
+		SessionRecorder.CaptureServo(float64(degree))
+		time.Sleep(scanSettleTime)
+
+		distance, err := HCSR04.MeasureDistance()
+		frame := scanFrame{
+			ScanID:   scanID,
+			TakenAt:  time.Now(),
+			Degree:   float64(degree),
+			Distance: distance,
+			Status:   sensorStatus(distance, err),
+		}
+
+		storeScanFrame(ctx, frame)
+		frames = append(frames, frame)
+	}
+
+	// Return to the position the servo was at before the sweep (or was commanded to during it)
+	for {
+		s.mu.Lock()
+		target := s.currentPos
+		if previous == target {
+			s.moving = false
+			s.mu.Unlock()
+			break
+		}
+		s.mu.Unlock()
+
+		s.Motor.MoveTo(s.trimmed(target)).Wait()
+		observeServoMove("scan", previous, target)
+		SessionRecorder.CaptureServo(target)
+		previous = target
+	}
+
+	PhoeniciaDigitalUtils.Logger.InfoContext(ctx, "Completed scan", "scan_id", scanID, "frames", len(frames))
+
+	return frames, nil
+}
@@ -0,0 +1,80 @@
+package source
+
+import (
+	PhoeniciaDigitalDatabase "Phoenicia-Digital-Base-API/base/database"
+	PhoeniciaDigitalUtils "Phoenicia-Digital-Base-API/base/utils"
+	PhoeniciaDigitalConfig "Phoenicia-Digital-Base-API/config"
+	"context"
+	"sync"
+	"time"
+)
+
+// The device name every stored reading & scan frame is tagged with so exports can be filtered per device
+// PROJECT_NAME defaults to Phoenicia-Digital when it is not set
+func deviceName() string {
+	return PhoeniciaDigitalConfig.Config().Project_Name
+}
+
+// Readings are written by a single writer reading this queue so a slow or unreachable database never stalls the
+// sampler | when the queue is full the reading is dropped with a warning instead
+const readingQueueSize int = 256
+
+var (
+	readingQueue  = make(chan map[string]any, readingQueueSize)
+	readingWriter sync.Once
+)
+
+// Queue a sensor sample to be stored in the readings table | ignored when Postgres is not enabled
+// Called once per sample by the sampler whatever the number of websocket clients
+func storeReading(data SensorData, degree float64) {
+	if !PhoeniciaDigitalDatabase.PostgresEnabled() {
+		return
+	}
+
+	readingWriter.Do(func() { go writeReadings() })
+
+	reading := map[string]any{
+		"device":   deviceName(),
+		"taken_at": time.Now(),
+		"distance": data.Distance,
+		"status":   data.Status,
+		"degree":   degree,
+	}
+
+	select {
+	case readingQueue <- reading:
+	default:
+		PhoeniciaDigitalUtils.Logger.Warn("Reading queue is full | Dropped the reading since the database is not keeping up", "queued", readingQueueSize)
+	}
+}
+
+// Insert the queued readings one at a time | errors are already logged by Exec so a failed insert is only counted
+// & skipped | the sampler runs for every client so there is no request ctx to insert with
+func writeReadings() {
+	for reading := range readingQueue {
+		if _, err := PhoeniciaDigitalDatabase.Postgres.Exec(context.Background(), "insert_reading", reading); err != nil {
+			storeErrors.Inc("readings")
+		}
+	}
+}
+
+// Store a frame of a sweep in the scan_frames table | ignored when Postgres is not enabled
+// Stored right away since a sweep already waits for the servo at every step & `main scan` exits once it is done
+// A failed insert is logged with the request ID of ctx by Exec & counted but does not stop the sweep
+func storeScanFrame(ctx context.Context, frame scanFrame) {
+	if !PhoeniciaDigitalDatabase.PostgresEnabled() {
+		return
+	}
+
+	_, err := PhoeniciaDigitalDatabase.Postgres.Exec(ctx, "insert_scan_frame", map[string]any{
+		"scan_id":  frame.ScanID,
+		"device":   deviceName(),
+		"taken_at": frame.TakenAt,
+		"degree":   frame.Degree,
+		"distance": frame.Distance,
+		"status":   frame.Status,
+	})
+	if err != nil {
+		storeErrors.Inc("scan_frames")
+	}
+}
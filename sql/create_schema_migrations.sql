@@ -0,0 +1,6 @@
+-- The migrations of sql/migrations applied to this database | managed by `main migrate` & the /migrations routes
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+SELECT scan_id, device, taken_at, degree, distance, status
+FROM scan_frames
+WHERE ($1::TIMESTAMPTZ IS NULL OR taken_at >= $1)
+  AND ($2::TIMESTAMPTZ IS NULL OR taken_at < $2)
+  AND ($3::TEXT IS NULL OR device = $3)
+ORDER BY taken_at, id;
@@ -1,5 +1,45 @@
 -- Use this File To Initialize The postgresql-service That Will Be Run By Docker
--- USE THIS TO CREATE TABLES!
+-- Schema changes go into numbered files of sql/migrations applied with `main migrate up` | Keep this file the same as
+-- the up migrations of sql/migrations one after the other so a new container starts with every one of them
 -- The Will Be Created Only On docker-compose --build
 -- Dont Forget To Do: GRANT INSERT, UPDATE, DELETE ON TABLE your_table TO your_user;
 -- \set my_variable 'some_value' -- Uncomment This And Set your_user For Ease Of Use
+
+
+-- Every sensor sample streamed to the websocket clients | Exported via GET /export/readings
+CREATE TABLE IF NOT EXISTS readings (
+    id BIGSERIAL PRIMARY KEY,
+    device TEXT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    distance DOUBLE PRECISION NOT NULL,
+    status TEXT NOT NULL,
+    degree DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS readings_device_taken_at_idx ON readings (device, taken_at);
+
+-- Every frame of a servo sweep started via `main scan` | Exported via GET /export/scans
+CREATE TABLE IF NOT EXISTS scan_frames (
+    id BIGSERIAL PRIMARY KEY,
+    scan_id TEXT NOT NULL,
+    device TEXT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    degree DOUBLE PRECISION NOT NULL,
+    distance DOUBLE PRECISION NOT NULL,
+    status TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS scan_frames_device_taken_at_idx ON scan_frames (device, taken_at);
+
+-- API keys accepted when AUTH_API_KEYS_POSTGRES=true | only the hex SHA-256 of a key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+-- 0002_api_keys_role | role is viewer | operator | admin (| separated for several) ex:
+-- INSERT INTO api_keys (name, key_hash, role) VALUES ('dashboard', '<echo -n key | sha256sum>', 'operator');
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'viewer';
@@ -0,0 +1 @@
+INSERT INTO readings (device, taken_at, distance, status, degree) VALUES (:device, :taken_at, :distance, :status, :degree);
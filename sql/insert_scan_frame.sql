@@ -0,0 +1 @@
+INSERT INTO scan_frames (scan_id, device, taken_at, degree, distance, status) VALUES (:scan_id, :device, :taken_at, :degree, :distance, :status);
@@ -0,0 +1,4 @@
+-- Drops every table of 0001_initial_schema.up.sql | THE STORED READINGS, SCAN FRAMES & API KEYS ARE LOST
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS scan_frames;
+DROP TABLE IF EXISTS readings;
@@ -0,0 +1,35 @@
+-- The tables of sql/init.sql | IF NOT EXISTS so databases the container already created from it can be migrated
+
+-- Every sensor sample streamed to the websocket clients | Exported via GET /export/readings
+CREATE TABLE IF NOT EXISTS readings (
+    id BIGSERIAL PRIMARY KEY,
+    device TEXT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    distance DOUBLE PRECISION NOT NULL,
+    status TEXT NOT NULL,
+    degree DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS readings_device_taken_at_idx ON readings (device, taken_at);
+
+-- Every frame of a servo sweep started via `main scan` | Exported via GET /export/scans
+CREATE TABLE IF NOT EXISTS scan_frames (
+    id BIGSERIAL PRIMARY KEY,
+    scan_id TEXT NOT NULL,
+    device TEXT NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    degree DOUBLE PRECISION NOT NULL,
+    distance DOUBLE PRECISION NOT NULL,
+    status TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS scan_frames_device_taken_at_idx ON scan_frames (device, taken_at);
+
+-- API keys accepted when AUTH_API_KEYS_POSTGRES=true | only the hex SHA-256 of a key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
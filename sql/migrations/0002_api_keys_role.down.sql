@@ -0,0 +1,2 @@
+-- Drops the role of every API key | the keys stored in Postgres are refused by select_api_key.sql until 0002 is applied again
+ALTER TABLE api_keys DROP COLUMN IF EXISTS role;
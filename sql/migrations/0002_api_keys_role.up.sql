@@ -0,0 +1,4 @@
+-- The role of every API key | viewer | operator | admin (| separated for several) ex:
+-- INSERT INTO api_keys (name, key_hash, role) VALUES ('dashboard', '<echo -n key | sha256sum>', 'operator');
+-- Keys stored before roles existed become viewers
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'viewer';
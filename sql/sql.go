@@ -0,0 +1,10 @@
+// File: `Embedded SQL File` sql/sql.go
+package PhoeniciaDigitalSQL
+
+import "embed"
+
+// Every query file & migration of this folder built into the binary so it runs from any directory without copying
+// ./sql next to it | POSTGRES_SQL_DIR reads a folder on disk instead while developing
+
+//go:embed *.sql migrations/*.sql
+var Files embed.FS